@@ -0,0 +1,318 @@
+package goga
+
+import (
+	"math/rand"
+	"sync"
+)
+
+const (
+	defaultLearningRate      = 0.3
+	defaultPursuitRate       = 0.3
+	defaultCreditHistorySize = 1000
+)
+
+// MaterFeedback - an optional interface for a Mater, or Reproducer, that wants to know how
+// the offspring it bred were scored, OnSimulated is called with every genome
+// once it has been simulated. It is called from the simulation goroutines
+// so must be safe for concurrent use
+type MaterFeedback interface {
+	OnSimulated(Genome)
+}
+
+// offspringReplacer - implemented by MaterFeedback that tracks the offspring it
+// bred, it is told when an offspring is replaced by a copy before being simulated,
+// such as when it is hypermutated, so the copy can be tracked in its place
+type offspringReplacer interface {
+	replaceOffspring(old, replacement Genome)
+}
+
+// AdaptationStrategy - how an adaptive mater turns the credit of
+// its operators into the probability of picking them
+type AdaptationStrategy int
+
+const (
+	// AdaptivePursuit moves the probability of the operator with the highest
+	// quality towards the largest it can be, and every other probability towards
+	// the floor, by PursuitRate each time an operator is credited
+	AdaptivePursuit AdaptationStrategy = iota
+	// ProbabilityMatching makes the probability of each operator, above the
+	// floor, proportional to its quality
+	ProbabilityMatching
+)
+
+// AdaptiveMaterConfig -
+// Configures how an adaptive mater learns which of its operators work best
+// * Strategy - AdaptivePursuit or ProbabilityMatching
+// * MinProbability - the floor of every operator's probability so none are
+// forgotten, less than 1 / the number of operators. 0 has no floor
+// * LearningRate - how quickly an operator's quality follows its recent credit, 0.3 if not set
+// * PursuitRate - how quickly AdaptivePursuit moves probabilities, 0.3 if not set
+// * HistorySize - the number of generations of credit kept for GetCreditHistory, 1000 if not set
+type AdaptiveMaterConfig struct {
+	Strategy       AdaptationStrategy
+	MinProbability float64
+	LearningRate   float64
+	PursuitRate    float64
+	HistorySize    int
+}
+
+// OperatorCredit - the credit each operator of an adaptive mater was given in one generation,
+// indexed in the order of the mater's config
+// * Generation - the generation the offspring were simulated in, counted by calls to OnElite
+// * Offspring - the number of offspring of each operator that were simulated
+// * Successes - the number of those offspring that were fitter than both of their parents
+// * Probabilities - the probability of picking each operator at the end of the generation
+type OperatorCredit struct {
+	Generation    int
+	Offspring     []int
+	Successes     []int
+	Probabilities []float64
+}
+
+// AdaptiveMater - a Mater that learns the probability of using each of its operators
+// * GetProbabilities - the current probability of picking each operator
+// * GetCreditHistory - the credit the operators were given in the most recent complete generations, oldest first
+type AdaptiveMater interface {
+	Mater
+	MaterFeedback
+	GetProbabilities() []float64
+	GetCreditHistory() []OperatorCredit
+}
+
+// offspringRecord - the operator that bred an offspring and the fitness it has to beat
+type offspringRecord struct {
+	operator      int
+	parentFitness int
+}
+
+type adaptiveMater struct {
+	materConfig   []MaterFunctionProbability
+	names         []string
+	config        AdaptiveMaterConfig
+	elite         Genome
+	probabilities []float64
+	qualities     []float64
+	current       OperatorCredit
+	history       []OperatorCredit
+
+	// Offspring bred this generation and last, so offspring still
+	// being simulated at the end of a generation are credited
+	pending  map[Genome]offspringRecord
+	previous map[Genome]offspringRecord
+	mutex    sync.Mutex
+}
+
+// NewAdaptiveMater returns an AdaptiveMater that, for each pair of parents, picks
+// one of the functions in 'materConfig' and applies it. The 'P' of each function
+// is its initial probability of being picked, relative to the others, and the
+// probabilities are then adapted by how often each function breeds offspring
+// that are fitter than both of their parents
+func NewAdaptiveMater(materConfig []MaterFunctionProbability, config AdaptiveMaterConfig) AdaptiveMater {
+	if config.LearningRate <= 0 {
+		config.LearningRate = defaultLearningRate
+	}
+	if config.PursuitRate <= 0 {
+		config.PursuitRate = defaultPursuitRate
+	}
+	if config.HistorySize <= 0 {
+		config.HistorySize = defaultCreditHistorySize
+	}
+
+	total := 0.0
+	for _, c := range materConfig {
+		total += float64(c.P)
+	}
+
+	probabilities := make([]float64, len(materConfig))
+	for i, c := range materConfig {
+		if total > 0 {
+			probabilities[i] = float64(c.P) / total
+		} else {
+			probabilities[i] = 1 / float64(len(materConfig))
+		}
+	}
+
+	m := &adaptiveMater{
+		materConfig:   materConfig,
+		names:         operatorNames(materConfig),
+		config:        config,
+		probabilities: probabilities,
+		qualities:     make([]float64, len(materConfig)),
+		pending:       make(map[Genome]offspringRecord),
+		previous:      make(map[Genome]offspringRecord),
+	}
+	m.current = m.newCredit(1)
+	return m
+}
+
+func (m *adaptiveMater) newCredit(generation int) OperatorCredit {
+	return OperatorCredit{
+		Generation: generation,
+		Offspring:  make([]int, len(m.materConfig)),
+		Successes:  make([]int, len(m.materConfig)),
+	}
+}
+
+// pick returns the index of a random operator, weighted by the probabilities
+func (m *adaptiveMater) pick() int {
+	r := rand.Float64()
+	for i, p := range m.probabilities {
+		r -= p
+		if r < 0 {
+			return i
+		}
+	}
+	return len(m.probabilities) - 1
+}
+
+// Go applies one operator, picked by its probability, to copies of 'g1' and 'g2'
+func (m *adaptiveMater) Go(g1, g2 Genome) (Genome, Genome) {
+	newG1 := newGenomeFrom(g1)
+	newG2 := newGenomeFrom(g2)
+	if len(m.materConfig) == 0 {
+		return newG1, newG2
+	}
+
+	m.mutex.Lock()
+	operator := m.pick()
+	elite := m.elite
+	m.mutex.Unlock()
+
+	config := m.materConfig[operator]
+	if config.UseElite {
+		newG1, newG2 = config.F(newG1, elite)
+	} else {
+		newG1, newG2 = config.F(newG1, newG2)
+	}
+	if recordsLineage(g1) {
+		operators := []string{m.names[operator]}
+		setOperators(newG1, operators)
+		setOperators(newG2, operators)
+	}
+
+	record := offspringRecord{
+		operator:      operator,
+		parentFitness: max(g1.GetFitness(), g2.GetFitness()),
+	}
+	m.mutex.Lock()
+	m.pending[newG1] = record
+	m.pending[newG2] = record
+	m.mutex.Unlock()
+
+	return newG1, newG2
+}
+
+// OnSimulated credits the operator that bred 'g', if it was bred by this mater
+func (m *adaptiveMater) OnSimulated(g Genome) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	record, ok := m.pending[g]
+	if ok {
+		delete(m.pending, g)
+	} else if record, ok = m.previous[g]; ok {
+		delete(m.previous, g)
+	} else {
+		return
+	}
+
+	reward := 0.0
+	m.current.Offspring[record.operator]++
+	if g.GetFitness() > record.parentFitness {
+		reward = 1
+		m.current.Successes[record.operator]++
+	}
+
+	m.qualities[record.operator] += m.config.LearningRate * (reward - m.qualities[record.operator])
+	m.adapt()
+}
+
+// replaceOffspring moves the record of 'old' to 'replacement', which is simulated in its place
+func (m *adaptiveMater) replaceOffspring(old, replacement Genome) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if record, ok := m.pending[old]; ok {
+		delete(m.pending, old)
+		m.pending[replacement] = record
+	} else if record, ok = m.previous[old]; ok {
+		delete(m.previous, old)
+		m.previous[replacement] = record
+	}
+}
+
+// adapt updates the probabilities from the qualities of the operators
+func (m *adaptiveMater) adapt() {
+	numOperators := float64(len(m.materConfig))
+	minP := m.config.MinProbability
+	if minP*numOperators > 1 {
+		minP = 1 / numOperators
+	}
+
+	switch m.config.Strategy {
+	case AdaptivePursuit:
+		best := 0
+		for i, q := range m.qualities {
+			if q > m.qualities[best] {
+				best = i
+			}
+		}
+		if m.qualities[best] <= 0 {
+			// No operator has bred a fitter offspring yet, so there is nothing to pursue
+			return
+		}
+
+		maxP := 1 - (numOperators-1)*minP
+		for i := range m.probabilities {
+			target := minP
+			if i == best {
+				target = maxP
+			}
+			m.probabilities[i] += m.config.PursuitRate * (target - m.probabilities[i])
+		}
+	case ProbabilityMatching:
+		total := 0.0
+		for _, q := range m.qualities {
+			total += q
+		}
+		for i, q := range m.qualities {
+			if total > 0 {
+				m.probabilities[i] = minP + (1-numOperators*minP)*q/total
+			} else {
+				m.probabilities[i] = 1 / numOperators
+			}
+		}
+	}
+}
+
+// OnElite records the elite for operators that use it and ends the current generation's credit
+func (m *adaptiveMater) OnElite(elite Genome) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.elite = elite
+
+	m.current.Probabilities = append([]float64(nil), m.probabilities...)
+	m.history = append(m.history, m.current)
+	if excess := len(m.history) - m.config.HistorySize; excess > 0 {
+		m.history = m.history[excess:]
+	}
+	m.current = m.newCredit(m.current.Generation + 1)
+
+	m.previous = m.pending
+	m.pending = make(map[Genome]offspringRecord)
+}
+
+// GetProbabilities returns the current probability of picking each operator
+func (m *adaptiveMater) GetProbabilities() []float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]float64(nil), m.probabilities...)
+}
+
+// GetCreditHistory returns the credit of the most recent complete generations, oldest first
+func (m *adaptiveMater) GetCreditHistory() []OperatorCredit {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]OperatorCredit(nil), m.history...)
+}
@@ -0,0 +1,190 @@
+package goga_test
+
+import (
+	"math"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type AdaptiveMaterSuite struct {
+}
+
+var _ = Suite(&AdaptiveMaterSuite{})
+
+func helperSetAllFunction(value int) func(goga.Genome, goga.Genome) (goga.Genome, goga.Genome) {
+	return func(g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+		b1, b2 := g1.GetBits().CreateCopy(), g2.GetBits().CreateCopy()
+		b1.SetAll(value)
+		b2.SetAll(value)
+		return goga.NewGenome(b1), goga.NewGenome(b2)
+	}
+}
+
+// helperAdapt mates genomes that are half ones with an operator that sets every
+// bit and one that clears every bit, with the fitness being the number of ones
+func helperAdapt(t *C, mater goga.AdaptiveMater, iterations int) {
+	for i := 0; i < iterations; i++ {
+		p1, p2 := helperGenomeFromString(t, "0011", 2), helperGenomeFromString(t, "0101", 2)
+		c1, c2 := mater.Go(p1, p2)
+		for _, c := range []goga.Genome{c1, c2} {
+			c.SetFitness(helperCountOnes(c.GetBits()))
+			mater.OnSimulated(c)
+		}
+	}
+}
+
+func helperAlmostEquals(t *C, actual, expected []float64) {
+	t.Assert(len(actual), Equals, len(expected))
+	for i := range actual {
+		t.Assert(math.Abs(actual[i]-expected[i]) < 0.01, IsTrue, Commentf("%v != %v", actual, expected))
+	}
+}
+
+func (s *AdaptiveMaterSuite) TestShouldStartWithRelativeProbabilities(t *C) {
+	mater := goga.NewAdaptiveMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.UniformCrossover},
+		{P: 3, F: goga.Mutate},
+	}, goga.AdaptiveMaterConfig{})
+	helperAlmostEquals(t, mater.GetProbabilities(), []float64{0.25, 0.75})
+
+	mater = goga.NewAdaptiveMater([]goga.MaterFunctionProbability{
+		{F: goga.UniformCrossover},
+		{F: goga.Mutate},
+	}, goga.AdaptiveMaterConfig{})
+	helperAlmostEquals(t, mater.GetProbabilities(), []float64{0.5, 0.5})
+}
+
+func (s *AdaptiveMaterSuite) TestShouldPursueTheBestOperator(t *C) {
+	mater := goga.NewAdaptiveMater([]goga.MaterFunctionProbability{
+		{P: 1, F: helperSetAllFunction(0)},
+		{P: 1, F: helperSetAllFunction(1)},
+	}, goga.AdaptiveMaterConfig{Strategy: goga.AdaptivePursuit, MinProbability: 0.1})
+
+	helperAdapt(t, mater, 200)
+	helperAlmostEquals(t, mater.GetProbabilities(), []float64{0.1, 0.9})
+}
+
+func (s *AdaptiveMaterSuite) TestShouldMatchProbabilitiesToQuality(t *C) {
+	mater := goga.NewAdaptiveMater([]goga.MaterFunctionProbability{
+		{P: 1, F: helperSetAllFunction(1)},
+		{P: 1, F: helperSetAllFunction(0)},
+		{P: 1, F: helperSetAllFunction(1)},
+	}, goga.AdaptiveMaterConfig{Strategy: goga.ProbabilityMatching, MinProbability: 0.05})
+
+	helperAdapt(t, mater, 200)
+	helperAlmostEquals(t, mater.GetProbabilities(), []float64{0.475, 0.05, 0.475})
+}
+
+func (s *AdaptiveMaterSuite) TestShouldIgnoreGenomesItDidNotBreed(t *C) {
+	mater := goga.NewAdaptiveMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.UniformCrossover},
+		{P: 1, F: goga.Mutate},
+	}, goga.AdaptiveMaterConfig{Strategy: goga.ProbabilityMatching})
+
+	mater.OnSimulated(helperGenomeFromString(t, "1111", 100))
+	mater.OnElite(helperGenomeFromString(t, "1111", 100))
+
+	helperAlmostEquals(t, mater.GetProbabilities(), []float64{0.5, 0.5})
+	history := mater.GetCreditHistory()
+	t.Assert(history, HasLen, 1)
+	t.Assert(history[0].Offspring, DeepEquals, []int{0, 0})
+}
+
+type MySimulatorCountOnes struct {
+	goga.NullSimulator
+}
+
+func (ms *MySimulatorCountOnes) Simulate(g goga.Genome) {
+	g.SetFitness(helperCountOnes(g.GetBits()))
+}
+
+func (s *AdaptiveMaterSuite) TestShouldRecordCreditEachGeneration(t *C) {
+	mater := goga.NewAdaptiveMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.UniformCrossover},
+		{P: 1, F: goga.Mutate},
+		{P: 1, F: goga.OnePointCrossover},
+	}, goga.AdaptiveMaterConfig{MinProbability: 0.1})
+
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Mater = mater
+	genAlgo.Simulator = &MySimulatorCountOnes{}
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(32)
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: goga.Roulette},
+	})
+	genAlgo.Terminator = goga.MaxGenerations(10)
+	genAlgo.Init(20, 2)
+	genAlgo.Simulate()
+
+	history := mater.GetCreditHistory()
+	t.Assert(history, HasLen, 10)
+	for i, credit := range history {
+		t.Assert(credit.Generation, Equals, i+1)
+
+		total := 0
+		for operator := range credit.Offspring {
+			total += credit.Offspring[operator]
+			t.Assert(credit.Successes[operator] <= credit.Offspring[operator], IsTrue)
+		}
+
+		// The initial population was not bred, every later generation was
+		if i == 0 {
+			t.Assert(total, Equals, 0)
+		} else {
+			t.Assert(total, Equals, 20)
+		}
+
+		sum := 0.0
+		for _, p := range credit.Probabilities {
+			t.Assert(p >= 0.1-1e-9, IsTrue)
+			sum += p
+		}
+		t.Assert(math.Abs(sum-1) < 1e-9, IsTrue)
+	}
+}
+
+func (s *AdaptiveMaterSuite) TestShouldCreditHypermutatedOffspring(t *C) {
+	mater := goga.NewAdaptiveMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.UniformCrossover},
+		{P: 1, F: goga.Mutate},
+	}, goga.AdaptiveMaterConfig{})
+
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Mater = mater
+	genAlgo.Simulator = &MySimulatorCountOnes{}
+	genAlgo.BitsetCreate = goga.NewConstantBitsetCreate(16, 0)
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: goga.Roulette},
+	})
+	genAlgo.Convergence = &goga.Convergence{
+		MinDiversity:             0.01,
+		Response:                 goga.Hypermutate,
+		HypermutationGenerations: 10,
+	}
+	genAlgo.Terminator = goga.MaxGenerations(5)
+	genAlgo.Init(20, 2)
+	genAlgo.Simulate()
+
+	// The initial population converged so every later generation was hypermutated
+	history := mater.GetCreditHistory()
+	t.Assert(history, HasLen, 5)
+	for _, credit := range history[1:] {
+		t.Assert(credit.Offspring[0]+credit.Offspring[1], Equals, 20)
+	}
+}
+
+func (s *AdaptiveMaterSuite) TestShouldLimitTheCreditHistory(t *C) {
+	mater := goga.NewAdaptiveMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.UniformCrossover},
+	}, goga.AdaptiveMaterConfig{HistorySize: 3})
+
+	for i := 0; i < 10; i++ {
+		mater.OnElite(helperGenomeFromString(t, "1111", 4))
+	}
+
+	history := mater.GetCreditHistory()
+	t.Assert(history, HasLen, 3)
+	t.Assert(history[0].Generation, Equals, 8)
+	t.Assert(history[2].Generation, Equals, 10)
+}
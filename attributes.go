@@ -0,0 +1,107 @@
+package goga
+
+import "sync"
+
+// AttributeGenome - an optional interface for genomes that carry named attributes
+// alongside their bits, such as the sub-scores or decoded phenotype computed for
+// them by a Simulator. Attributes can be set and got from any goroutine, including
+// from Simulate, and are kept when the genome is copied with CopyGenome but not by
+// the offspring bred from it, whose bits differ
+// * SetAttribute - sets the value of the attribute 'key'
+// * GetAttribute - returns the value of the attribute 'key', and false if it is not set
+// * GetAttributes - returns a copy of every attribute
+type AttributeGenome interface {
+	SetAttribute(key string, value interface{})
+	GetAttribute(key string) (interface{}, bool)
+	GetAttributes() map[string]interface{}
+}
+
+// Attributes - a thread-safe implementation of AttributeGenome that genomes
+// can embed, the zero value has no attributes and is ready to use.
+// It is embedded in the genomes of this package and gp.TreeGenome
+type Attributes struct {
+	mutex  sync.Mutex
+	values map[string]interface{}
+}
+
+// SetAttribute sets the value of the attribute 'key'
+func (a *Attributes) SetAttribute(key string, value interface{}) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.values == nil {
+		a.values = make(map[string]interface{})
+	}
+	a.values[key] = value
+}
+
+// GetAttribute returns the value of the attribute 'key', and false if it is not set
+func (a *Attributes) GetAttribute(key string) (interface{}, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	value, ok := a.values[key]
+	return value, ok
+}
+
+// GetAttributes returns a copy of every attribute
+func (a *Attributes) GetAttributes() map[string]interface{} {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	ret := make(map[string]interface{}, len(a.values))
+	for key, value := range a.values {
+		ret[key] = value
+	}
+	return ret
+}
+
+// copyAttributes sets every attribute of 'from' on 'to', if both carry attributes
+func copyAttributes(from, to Genome) {
+	fromAttributes, fromOk := from.(AttributeGenome)
+	toAttributes, toOk := to.(AttributeGenome)
+	if !fromOk || !toOk {
+		return
+	}
+	for key, value := range fromAttributes.GetAttributes() {
+		toAttributes.SetAttribute(key, value)
+	}
+}
+
+// PhenotypeCache - decodes genomes into their phenotype, the form their bits
+// describe such as an image or a string, and caches it as an attribute of the
+// genome. This allows the phenotype decoded to score a genome in Simulate to be
+// reused in EliteConsumer.OnElite rather than being decoded again
+type PhenotypeCache struct {
+	key    string
+	decode func(Genome) interface{}
+}
+
+// NewPhenotypeCache returns a PhenotypeCache that decodes genomes with 'decode' and
+// caches the phenotype under the attribute 'key', each kind of phenotype needs its own key
+// i.e.
+// images := NewPhenotypeCache("image", func(g Genome) interface{} { return decodeImage(g.GetBits()) })
+// img := images.Get(g).(image.Image)
+func NewPhenotypeCache(key string, decode func(Genome) interface{}) *PhenotypeCache {
+	return &PhenotypeCache{
+		key:    key,
+		decode: decode,
+	}
+}
+
+// Get returns the phenotype of 'g', decoding it the first time it is asked for.
+// Genomes that do not implement AttributeGenome are decoded every time.
+// The phenotype is shared by every caller so must not be modified
+func (pc *PhenotypeCache) Get(g Genome) interface{} {
+	attributes, ok := g.(AttributeGenome)
+	if !ok {
+		return pc.decode(g)
+	}
+
+	if phenotype, ok := attributes.GetAttribute(pc.key); ok {
+		return phenotype
+	}
+	phenotype := pc.decode(g)
+	attributes.SetAttribute(pc.key, phenotype)
+	return phenotype
+}
@@ -0,0 +1,143 @@
+package goga_test
+
+import (
+	"sync"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type AttributesSuite struct {
+}
+
+var _ = Suite(&AttributesSuite{})
+
+func helperAttributes(g goga.Genome) goga.AttributeGenome {
+	return g.(goga.AttributeGenome)
+}
+
+func (s *AttributesSuite) TestShouldSetGetAttributes(t *C) {
+	g := helperAttributes(helperGenome(t, "0101"))
+	_, ok := g.GetAttribute("score")
+	t.Assert(ok, IsFalse)
+	t.Assert(g.GetAttributes(), HasLen, 0)
+
+	g.SetAttribute("score", 10)
+	g.SetAttribute("log", "drew 2 shapes")
+	value, ok := g.GetAttribute("score")
+	t.Assert(ok, IsTrue)
+	t.Assert(value, Equals, 10)
+
+	// The map returned is a copy
+	attributes := g.GetAttributes()
+	t.Assert(attributes, DeepEquals, map[string]interface{}{"score": 10, "log": "drew 2 shapes"})
+	attributes["score"] = 20
+	value, _ = g.GetAttribute("score")
+	t.Assert(value, Equals, 10)
+}
+
+func (s *AttributesSuite) TestShouldCopyAttributes(t *C) {
+	g := helperGenome(t, "0101")
+	helperAttributes(g).SetAttribute("score", 10)
+
+	copied := goga.CopyGenome(g)
+	t.Assert(helperAttributes(copied).GetAttributes(), DeepEquals, map[string]interface{}{"score": 10})
+
+	// Changing the copy's attributes doesn't change the original
+	helperAttributes(copied).SetAttribute("score", 20)
+	value, _ := helperAttributes(g).GetAttribute("score")
+	t.Assert(value, Equals, 10)
+
+	// Offspring have different bits so start without attributes
+	mater := goga.NewMater([]goga.MaterFunctionProbability{{P: 1, F: goga.Mutate}})
+	c1, c2 := mater.Go(g, g)
+	t.Assert(helperAttributes(c1).GetAttributes(), HasLen, 0)
+	t.Assert(helperAttributes(c2).GetAttributes(), HasLen, 0)
+}
+
+type MyGenomeWithoutAttributes struct {
+	goga.Genome
+}
+
+func (s *AttributesSuite) TestShouldCachePhenotypes(t *C) {
+	var mutex sync.Mutex
+	numDecoded := 0
+	cache := goga.NewPhenotypeCache("string", func(g goga.Genome) interface{} {
+		mutex.Lock()
+		numDecoded++
+		mutex.Unlock()
+		return g.GetBits().String()
+	})
+
+	g := helperGenome(t, "0101")
+	t.Assert(cache.Get(g), Equals, "0101")
+	t.Assert(cache.Get(g), Equals, "0101")
+	t.Assert(numDecoded, Equals, 1)
+
+	// Genomes that can't hold the phenotype are decoded every time
+	withoutAttributes := &MyGenomeWithoutAttributes{g}
+	t.Assert(cache.Get(withoutAttributes), Equals, "0101")
+	t.Assert(cache.Get(withoutAttributes), Equals, "0101")
+	t.Assert(numDecoded, Equals, 3)
+
+	// Each cache has its own key
+	other := goga.NewPhenotypeCache("size", func(g goga.Genome) interface{} {
+		return g.GetBits().GetSize()
+	})
+	t.Assert(other.Get(g), Equals, 4)
+	t.Assert(cache.Get(g), Equals, "0101")
+}
+
+type MySimulatorPhenotype struct {
+	MySimulatorCountOnes
+	cache *goga.PhenotypeCache
+}
+
+func (ms *MySimulatorPhenotype) Simulate(g goga.Genome) {
+	g.SetFitness(ms.cache.Get(g).(int))
+	helperAttributes(g).SetAttribute("simulated", true)
+}
+
+type MyEliteConsumerPhenotype struct {
+	cache     *goga.PhenotypeCache
+	phenotype []int
+	simulated []bool
+}
+
+func (ec *MyEliteConsumerPhenotype) OnElite(g goga.Genome) {
+	ec.phenotype = append(ec.phenotype, ec.cache.Get(g).(int))
+	simulated, _ := helperAttributes(g).GetAttribute("simulated")
+	ec.simulated = append(ec.simulated, simulated.(bool))
+}
+
+func (s *AttributesSuite) TestShouldReusePhenotypeDecodedInSimulate(t *C) {
+	var mutex sync.Mutex
+	numDecoded := 0
+	cache := goga.NewPhenotypeCache("ones", func(g goga.Genome) interface{} {
+		mutex.Lock()
+		numDecoded++
+		mutex.Unlock()
+		return helperCountOnes(g.GetBits())
+	})
+	eliteConsumer := &MyEliteConsumerPhenotype{cache: cache}
+
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorPhenotype{cache: cache}
+	genAlgo.EliteConsumer = eliteConsumer
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(16)
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.UniformCrossover},
+		{P: 1, F: goga.Mutate},
+	})
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: goga.Roulette},
+	})
+	genAlgo.Terminator = goga.MaxGenerations(5)
+	genAlgo.Init(10, 4)
+	genAlgo.Simulate()
+
+	// Every genome was decoded once, when it was simulated
+	t.Assert(numDecoded, Equals, 50)
+	t.Assert(eliteConsumer.phenotype, DeepEquals, genAlgo.GetRunState().EliteHistory)
+	t.Assert(eliteConsumer.simulated, DeepEquals, []bool{true, true, true, true, true})
+}
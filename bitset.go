@@ -1,5 +1,9 @@
 package goga
 
+import (
+	"fmt"
+)
+
 // Bitset - a simple bitset implementation
 type Bitset struct {
 	size int
@@ -69,3 +73,34 @@ func (b *Bitset) Slice(startingBit, size int) Bitset {
 	ret.bits = b.bits[startingBit : startingBit+size]
 	return ret
 }
+
+// String returns the bitset as a string of '0' and '1' characters,
+// the first character being the bit at index 0
+func (b *Bitset) String() string {
+	ret := make([]byte, b.size)
+	for i := 0; i < b.size; i++ {
+		if b.bits[i] == 0 {
+			ret[i] = '0'
+		} else {
+			ret[i] = '1'
+		}
+	}
+	return string(ret)
+}
+
+// ParseBitset creates a bitset from a string of '0' and '1' characters
+// in the format produced by String
+func ParseBitset(s string) (Bitset, error) {
+	ret := Bitset{}
+	ret.Create(len(s))
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '0':
+		case '1':
+			ret.setImpl(i, 1)
+		default:
+			return Bitset{}, fmt.Errorf("invalid character %q at index %v in bitset", s[i], i)
+		}
+	}
+	return ret, nil
+}
@@ -1,5 +1,11 @@
 package goga
 
+import (
+	"math/bits"
+	"math/rand"
+	"sync"
+)
+
 // BitsetCreate - an interface to a bitset create struct
 type BitsetCreate interface {
 	Go() Bitset
@@ -13,3 +19,251 @@ type NullBitsetCreate struct {
 func (ngc *NullBitsetCreate) Go() Bitset {
 	return Bitset{}
 }
+
+type randomBitsetCreate struct {
+	size int
+	p    float64
+}
+
+// NewRandomBitsetCreate returns a BitsetCreate that creates bitsets of
+// 'size' uniformly random bits
+func NewRandomBitsetCreate(size int) BitsetCreate {
+	return &randomBitsetCreate{size: size, p: 0.5}
+}
+
+// NewBiasedBitsetCreate returns a BitsetCreate that creates bitsets of
+// 'size' random bits, each bit is 1 with probability 'p'
+func NewBiasedBitsetCreate(size int, p float64) BitsetCreate {
+	return &randomBitsetCreate{size: size, p: p}
+}
+
+func (rbc *randomBitsetCreate) Go() Bitset {
+	b := Bitset{}
+	b.Create(rbc.size)
+	for i := 0; i < rbc.size; i++ {
+		if rand.Float64() < rbc.p {
+			b.Set(i, 1)
+		}
+	}
+	return b
+}
+
+type constantBitsetCreate struct {
+	size  int
+	value int
+}
+
+// NewConstantBitsetCreate returns a BitsetCreate that creates bitsets of
+// 'size' bits that are all set to 'value', either 0 or 1
+func NewConstantBitsetCreate(size, value int) BitsetCreate {
+	return &constantBitsetCreate{size: size, value: value}
+}
+
+func (cbc *constantBitsetCreate) Go() Bitset {
+	b := Bitset{}
+	b.Create(cbc.size)
+	if cbc.value != 0 {
+		b.SetAll(1)
+	}
+	return b
+}
+
+type seededBitsetCreate struct {
+	seeds               []Bitset
+	mutationProbability float64
+	next                int
+	mutex               sync.Mutex
+}
+
+// NewSeededBitsetCreate returns a BitsetCreate that takes each of 'seeds' in turn
+// and returns a copy of it with each bit flipped with probability 'mutationProbability'.
+// This is useful for starting a simulation from known good solutions
+func NewSeededBitsetCreate(seeds []Bitset, mutationProbability float64) BitsetCreate {
+	return &seededBitsetCreate{
+		seeds:               seeds,
+		mutationProbability: mutationProbability,
+	}
+}
+
+func (sbc *seededBitsetCreate) Go() Bitset {
+	if len(sbc.seeds) == 0 {
+		return Bitset{}
+	}
+
+	sbc.mutex.Lock()
+	seed := sbc.seeds[sbc.next]
+	sbc.next = (sbc.next + 1) % len(sbc.seeds)
+	sbc.mutex.Unlock()
+
+	b := seed.CreateCopy()
+	for i := 0; i < b.GetSize(); i++ {
+		if rand.Float64() < sbc.mutationProbability {
+			b.Set(i, 1-b.Get(i))
+		}
+	}
+	return b
+}
+
+type stratifiedBitsetCreate struct {
+	fields    []Field
+	size      int
+	numStrata int
+	strata    [][]int
+	next      int
+	mutex     sync.Mutex
+}
+
+// NewStratifiedBitsetCreate returns a BitsetCreate that spreads the fields of 'parse'
+// evenly over their ranges, in the style of a latin hypercube. The range of each field
+// is divided into 'numStrata' strata, usually the population size, and every
+// 'numStrata' bitsets created contain a value from each stratum of every field,
+// with the strata of different fields paired at random.
+// Strata are taken over the order of the decoded values, so Gray and signed fields
+// are spread over their decoded ranges rather than their raw bits
+func NewStratifiedBitsetCreate(parse BitsetParse, numStrata int) BitsetCreate {
+	if numStrata < 1 {
+		numStrata = 1
+	}
+	return &stratifiedBitsetCreate{
+		fields:    parse.GetFields(),
+		size:      parse.GetSize(),
+		numStrata: numStrata,
+	}
+}
+
+func (sbc *stratifiedBitsetCreate) Go() Bitset {
+	sbc.mutex.Lock()
+	if sbc.next == 0 {
+		sbc.strata = make([][]int, len(sbc.fields))
+		for i := range sbc.strata {
+			sbc.strata[i] = rand.Perm(sbc.numStrata)
+		}
+	}
+	stratumIndex := sbc.next
+	sbc.next = (sbc.next + 1) % sbc.numStrata
+
+	strata := make([]int, len(sbc.fields))
+	for i := range strata {
+		strata[i] = sbc.strata[i][stratumIndex]
+	}
+	sbc.mutex.Unlock()
+
+	b := Bitset{}
+	b.Create(sbc.size)
+	runningBits := 0
+	for i, field := range sbc.fields {
+		raw := field.orderedToRaw(randomInStratum(field.Bits, strata[i], sbc.numStrata))
+		for bit := 0; bit < field.Bits; bit++ {
+			b.Set(runningBits+bit, int((raw>>uint(bit))&1))
+		}
+		runningBits += field.Bits
+	}
+	return b
+}
+
+// stratumStart returns the first of the 2^numBits values that is in 'stratum'
+func stratumStart(numBits int, stratum, numStrata int) uint64 {
+	hi, lo := uint64(stratum), uint64(0)
+	if numBits < 64 {
+		hi, lo = uint64(stratum)>>uint(64-numBits), uint64(stratum)<<uint(numBits)
+	}
+	quotient, _ := bits.Div64(hi, lo, uint64(numStrata))
+	return quotient
+}
+
+// randomInStratum returns a random value of 'numBits' bits from 'stratum',
+// strata are smaller than a single value when there are more strata than values
+func randomInStratum(numBits int, stratum, numStrata int) uint64 {
+	start := stratumStart(numBits, stratum, numStrata)
+	end := maxFieldValue(numBits)
+	if stratum+1 < numStrata {
+		next := stratumStart(numBits, stratum+1, numStrata)
+		if next <= start {
+			return start
+		}
+		end = next - 1
+	}
+
+	width := end - start + 1
+	if width == 0 {
+		// The stratum covers all 64 bits
+		return rand.Uint64()
+	}
+	return start + rand.Uint64()%width
+}
+
+// orderedToRaw converts the position of a value in the field's decoded
+// order into the bits that represent it
+func (f *Field) orderedToRaw(ordered uint64) uint64 {
+	switch f.Encoding {
+	case EncodingGray:
+		return binaryToGray(ordered)
+	case EncodingSigned, EncodingFixedPoint:
+		return ordered ^ (uint64(1) << uint(f.Bits-1))
+	}
+	return ordered
+}
+
+type diverseBitsetCreate struct {
+	size       int
+	candidates int
+	memory     int
+	created    []Bitset
+	next       int
+	mutex      sync.Mutex
+}
+
+// NewDiverseBitsetCreate returns a BitsetCreate that spreads bitsets of 'size' bits
+// apart from each other. Each call generates 'candidates' random bitsets and returns
+// the one whose smallest Hamming distance to the most recent 'memory' bitsets created
+// is largest, 'memory' is usually the population size
+func NewDiverseBitsetCreate(size, candidates, memory int) BitsetCreate {
+	if candidates < 1 {
+		candidates = 1
+	}
+	if memory < 1 {
+		memory = 1
+	}
+	return &diverseBitsetCreate{size: size, candidates: candidates, memory: memory}
+}
+
+func (dbc *diverseBitsetCreate) Go() Bitset {
+	random := NewRandomBitsetCreate(dbc.size)
+
+	dbc.mutex.Lock()
+	defer dbc.mutex.Unlock()
+
+	var best Bitset
+	bestDistance := -1
+	for i := 0; i < dbc.candidates; i++ {
+		candidate := random.Go()
+		distance := dbc.size + 1
+		for j := range dbc.created {
+			if d := hammingDistance(&candidate, &dbc.created[j]); d < distance {
+				distance = d
+			}
+		}
+		if distance > bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	// Once 'memory' bitsets have been created the oldest is replaced
+	if len(dbc.created) < dbc.memory {
+		dbc.created = append(dbc.created, best)
+	} else {
+		dbc.created[dbc.next] = best
+		dbc.next = (dbc.next + 1) % dbc.memory
+	}
+	return best.CreateCopy()
+}
+
+func hammingDistance(a, b *Bitset) int {
+	distance := 0
+	for i := 0; i < a.GetSize() && i < b.GetSize(); i++ {
+		if a.Get(i) != b.Get(i) {
+			distance++
+		}
+	}
+	return distance
+}
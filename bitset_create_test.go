@@ -0,0 +1,181 @@
+package goga_test
+
+import (
+	"math"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type BitsetCreateSuite struct {
+}
+
+var _ = Suite(&BitsetCreateSuite{})
+
+func helperCountOnes(b *goga.Bitset) int {
+	ones := 0
+	for i := 0; i < b.GetSize(); i++ {
+		ones += b.Get(i)
+	}
+	return ones
+}
+
+func (s *BitsetCreateSuite) TestShouldCreateRandomBitsets(t *C) {
+	create := goga.NewRandomBitsetCreate(1000)
+
+	b := create.Go()
+	t.Assert(b.GetSize(), Equals, 1000)
+
+	ones := helperCountOnes(&b)
+	t.Assert(ones > 400 && ones < 600, IsTrue)
+}
+
+func (s *BitsetCreateSuite) TestShouldCreateBiasedBitsets(t *C) {
+	b := goga.NewBiasedBitsetCreate(1000, 0.9).Go()
+	t.Assert(b.GetSize(), Equals, 1000)
+
+	ones := helperCountOnes(&b)
+	t.Assert(ones > 800 && ones < 980, IsTrue)
+
+	b = goga.NewBiasedBitsetCreate(100, 0).Go()
+	t.Assert(helperCountOnes(&b), Equals, 0)
+}
+
+func (s *BitsetCreateSuite) TestShouldCreateConstantBitsets(t *C) {
+	b := goga.NewConstantBitsetCreate(10, 0).Go()
+	t.Assert(b.String(), Equals, "0000000000")
+
+	b = goga.NewConstantBitsetCreate(10, 1).Go()
+	t.Assert(b.String(), Equals, "1111111111")
+}
+
+func (s *BitsetCreateSuite) TestShouldCreateFromSeeds(t *C) {
+	seeds := []goga.Bitset{*helperBitset(t, "0000"), *helperBitset(t, "1111")}
+	create := goga.NewSeededBitsetCreate(seeds, 0)
+
+	for i := 0; i < 4; i++ {
+		b := create.Go()
+		t.Assert(b.String(), Equals, seeds[i%2].String())
+	}
+
+	// Created bitsets are copies of the seeds
+	b := create.Go()
+	b.Set(0, 1)
+	t.Assert(seeds[0].String(), Equals, "0000")
+}
+
+func (s *BitsetCreateSuite) TestShouldMutateSeeds(t *C) {
+	seeds := []goga.Bitset{*helperBitset(t, "1111")}
+
+	b := goga.NewSeededBitsetCreate(seeds, 1).Go()
+	t.Assert(b.String(), Equals, "0000")
+
+	seed := goga.Bitset{}
+	seed.Create(1000)
+	b = goga.NewSeededBitsetCreate([]goga.Bitset{seed}, 0.1).Go()
+
+	ones := helperCountOnes(&b)
+	t.Assert(ones > 50 && ones < 150, IsTrue)
+}
+
+func (s *BitsetCreateSuite) TestShouldCreateEmptyBitsetWithoutSeeds(t *C) {
+	b := goga.NewSeededBitsetCreate(nil, 0).Go()
+	t.Assert(b.GetSize(), Equals, 0)
+}
+
+func (s *BitsetCreateSuite) TestShouldStratifyFields(t *C) {
+	parse := goga.CreateBitsetParse()
+	parse.SetFields([]goga.Field{
+		{Bits: 8},
+		{Bits: 6, Encoding: goga.EncodingGray},
+		{Bits: 8, Encoding: goga.EncodingScaledFloat, Min: -1, Max: 1},
+		{Bits: 64},
+	})
+
+	numStrata := 4
+	create := goga.NewStratifiedBitsetCreate(parse, numStrata)
+
+	for round := 0; round < 3; round++ {
+		strata := make([][]bool, 4)
+		for i := range strata {
+			strata[i] = make([]bool, numStrata)
+		}
+
+		for i := 0; i < numStrata; i++ {
+			b := create.Go()
+			t.Assert(b.GetSize(), Equals, parse.GetSize())
+
+			fields := parse.ProcessFields(&b)
+			strata[0][fields[0].(uint64)/64] = true
+			strata[1][fields[1].(uint64)/16] = true
+			strata[2][int(math.Min((fields[2].(float64)+1)*2, 3))] = true
+			strata[3][fields[3].(uint64)>>62] = true
+		}
+
+		// Every stratum of every field is used once in each set of 'numStrata' bitsets
+		for _, fieldStrata := range strata {
+			t.Assert(fieldStrata, DeepEquals, []bool{true, true, true, true})
+		}
+	}
+}
+
+func (s *BitsetCreateSuite) TestShouldStratifySignedFieldsOverTheirDecodedRange(t *C) {
+	parse := goga.CreateBitsetParse()
+	parse.SetFields([]goga.Field{{Bits: 4, Encoding: goga.EncodingSigned}})
+
+	create := goga.NewStratifiedBitsetCreate(parse, 2)
+	negative, positive := 0, 0
+	for i := 0; i < 10; i++ {
+		b := create.Go()
+		if parse.ProcessFields(&b)[0].(int64) < 0 {
+			negative++
+		} else {
+			positive++
+		}
+	}
+	t.Assert(negative, Equals, 5)
+	t.Assert(positive, Equals, 5)
+}
+
+func (s *BitsetCreateSuite) TestShouldStratifyWithMoreStrataThanValues(t *C) {
+	parse := goga.CreateBitsetParse()
+	parse.SetFields([]goga.Field{{Bits: 1}})
+
+	create := goga.NewStratifiedBitsetCreate(parse, 10)
+	ones := 0
+	for i := 0; i < 10; i++ {
+		b := create.Go()
+		ones += b.Get(0)
+	}
+	t.Assert(ones, Equals, 5)
+}
+
+func (s *BitsetCreateSuite) TestShouldCreateDiverseBitsets(t *C) {
+	create := goga.NewDiverseBitsetCreate(16, 50, 10)
+
+	a := create.Go()
+	b := create.Go()
+
+	distance := 0
+	for i := 0; i < 16; i++ {
+		if a.Get(i) != b.Get(i) {
+			distance++
+		}
+	}
+
+	// Random bitsets are 8 bits apart on average, picking the furthest of 50 candidates
+	// makes a distance this small very unlikely
+	t.Assert(distance >= 10, IsTrue)
+}
+
+func (s *BitsetCreateSuite) TestShouldOnlySpreadFromRecentBitsets(t *C) {
+	create := goga.NewDiverseBitsetCreate(2, 100, 1)
+
+	// Each bitset is as far as it can be from the last, so the third is the first
+	// again even though it is no distance from it
+	a := create.Go()
+	b := create.Go()
+	c := create.Go()
+	t.Assert(b.String(), Not(Equals), a.String())
+	t.Assert(c.String(), Equals, a.String())
+}
@@ -0,0 +1,160 @@
+package goga
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// BitsetFileFormat - how ReadBitsets and WriteBitsets store each bitset on its line
+type BitsetFileFormat int
+
+const (
+	// BitsetFormatBinary stores a bitset as '0' and '1' characters, as produced by Bitset.String
+	BitsetFormatBinary BitsetFileFormat = iota
+
+	// BitsetFormatHex stores a bitset as hex, as produced by Bitset.Hex
+	BitsetFormatHex
+)
+
+const hexDigits = "0123456789abcdef"
+
+// Hex returns the bitset as "0x" followed by a hex digit for every 4 bits, the
+// first digit holding bits 0 to 3 with bit 0 as its least significant bit.
+// If the size of the bitset is not a multiple of 4 it is appended after a ':'
+func (b *Bitset) Hex() string {
+	numDigits := (b.size + 3) / 4
+	ret := make([]byte, numDigits)
+	for digit := 0; digit < numDigits; digit++ {
+		value := 0
+		for bit := 0; bit < 4; bit++ {
+			if index := digit*4 + bit; index < b.size && b.bits[index] != 0 {
+				value |= 1 << uint(bit)
+			}
+		}
+		ret[digit] = hexDigits[value]
+	}
+
+	if b.size%4 != 0 {
+		return fmt.Sprintf("0x%s:%v", ret, b.size)
+	}
+	return "0x" + string(ret)
+}
+
+// ParseBitsetHex creates a bitset from a string in the format produced by Hex
+func ParseBitsetHex(s string) (Bitset, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return Bitset{}, fmt.Errorf("hex bitset %q does not start with 0x", s)
+	}
+	s = s[2:]
+
+	size := len(s) * 4
+	if colon := strings.IndexByte(s, ':'); colon >= 0 {
+		sizeString := s[colon+1:]
+		s = s[:colon]
+
+		var err error
+		size, err = strconv.Atoi(sizeString)
+		if err != nil || size > len(s)*4 || size <= (len(s)-1)*4 {
+			return Bitset{}, fmt.Errorf("invalid size %q for %v hex digits", sizeString, len(s))
+		}
+	}
+
+	ret := Bitset{}
+	ret.Create(size)
+	for digit := 0; digit < len(s); digit++ {
+		value, err := strconv.ParseUint(s[digit:digit+1], 16, 8)
+		if err != nil {
+			return Bitset{}, fmt.Errorf("invalid character %q at index %v in hex bitset", s[digit], digit)
+		}
+		for bit := 0; bit < 4; bit++ {
+			if value&(1<<uint(bit)) != 0 {
+				if !ret.Set(digit*4+bit, 1) {
+					return Bitset{}, fmt.Errorf("hex bitset has bits set beyond its size %v", size)
+				}
+			}
+		}
+	}
+	return ret, nil
+}
+
+// ReadBitsets reads one bitset per line from 'r'. Lines starting with "0x" are read
+// as hex, other lines as '0' and '1' characters. Blank lines and lines starting
+// with '#' are ignored
+func ReadBitsets(r io.Reader) ([]Bitset, error) {
+	var ret []Bitset
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024*64)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var b Bitset
+		var err error
+		if strings.HasPrefix(line, "0x") {
+			b, err = ParseBitsetHex(line)
+		} else {
+			b, err = ParseBitset(line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", lineNumber, err)
+		}
+		ret = append(ret, b)
+	}
+	return ret, scanner.Err()
+}
+
+// WriteBitsets writes one bitset per line to 'w' in 'format'
+func WriteBitsets(w io.Writer, bitsets []Bitset, format BitsetFileFormat) error {
+	writer := bufio.NewWriter(w)
+	for i := range bitsets {
+		line := bitsets[i].String()
+		if format == BitsetFormatHex {
+			line = bitsets[i].Hex()
+		}
+		if _, err := fmt.Fprintln(writer, line); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// ReadBitsetsFile reads the bitsets in the file at 'path', see ReadBitsets
+func ReadBitsetsFile(path string) ([]Bitset, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadBitsets(file)
+}
+
+// WriteBitsetsFile creates, or truncates, the file at 'path' and writes 'bitsets' to it
+func WriteBitsetsFile(path string, bitsets []Bitset, format BitsetFileFormat) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteBitsets(file, bitsets, format); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// GetBitsets returns a copy of the bitset of each of 'genomes', for example to
+// write the genomes in a HallOfFame to a file
+func GetBitsets(genomes []Genome) []Bitset {
+	ret := make([]Bitset, len(genomes))
+	for i, g := range genomes {
+		ret[i] = g.GetBits().CreateCopy()
+	}
+	return ret
+}
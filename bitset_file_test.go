@@ -0,0 +1,97 @@
+package goga_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type BitsetFileSuite struct {
+}
+
+var _ = Suite(&BitsetFileSuite{})
+
+func (s *BitsetFileSuite) TestShouldFormatHex(t *C) {
+	t.Assert(helperBitset(t, "10000101").Hex(), Equals, "0x1a")
+	t.Assert(helperBitset(t, "1111000011").Hex(), Equals, "0xf03:10")
+
+	empty := goga.Bitset{}
+	t.Assert(empty.Hex(), Equals, "0x")
+}
+
+func (s *BitsetFileSuite) TestShouldParseHex(t *C) {
+	for _, bits := range []string{"", "1", "10000101", "1111000011", "0000000"} {
+		b := helperBitset(t, bits)
+		parsed, err := goga.ParseBitsetHex(b.Hex())
+		t.Assert(err, IsNil)
+		t.Assert(parsed.String(), Equals, bits)
+	}
+
+	parsed, err := goga.ParseBitsetHex("0xF")
+	t.Assert(err, IsNil)
+	t.Assert(parsed.String(), Equals, "1111")
+}
+
+func (s *BitsetFileSuite) TestShouldNotParseInvalidHex(t *C) {
+	for _, hex := range []string{"1a", "0xg", "0x1:5", "0x11:4", "0x1:x", "0xf:2"} {
+		_, err := goga.ParseBitsetHex(hex)
+		t.Assert(err, NotNil, Commentf(hex))
+	}
+}
+
+func (s *BitsetFileSuite) TestShouldReadBitsets(t *C) {
+	input := "# a comment\n0101\n\n0x3:3\n  111  \n"
+	bitsets, err := goga.ReadBitsets(strings.NewReader(input))
+	t.Assert(err, IsNil)
+	t.Assert(bitsets, HasLen, 3)
+	t.Assert(bitsets[0].String(), Equals, "0101")
+	t.Assert(bitsets[1].String(), Equals, "110")
+	t.Assert(bitsets[2].String(), Equals, "111")
+
+	_, err = goga.ReadBitsets(strings.NewReader("0101\n012\n"))
+	t.Assert(err, ErrorMatches, "line 2: .*")
+}
+
+func (s *BitsetFileSuite) TestShouldWriteBitsets(t *C) {
+	bitsets := []goga.Bitset{*helperBitset(t, "0101"), *helperBitset(t, "110")}
+
+	buffer := bytes.Buffer{}
+	t.Assert(goga.WriteBitsets(&buffer, bitsets, goga.BitsetFormatBinary), IsNil)
+	t.Assert(buffer.String(), Equals, "0101\n110\n")
+
+	buffer.Reset()
+	t.Assert(goga.WriteBitsets(&buffer, bitsets, goga.BitsetFormatHex), IsNil)
+	t.Assert(buffer.String(), Equals, "0xa\n0x3:3\n")
+}
+
+func (s *BitsetFileSuite) TestShouldRoundTripBitsetFiles(t *C) {
+	path := filepath.Join(t.MkDir(), "bitsets.txt")
+
+	genomes := []goga.Genome{
+		goga.NewGenome(*helperBitset(t, "0101")),
+		goga.NewGenome(*helperBitset(t, "111000111")),
+	}
+	bitsets := goga.GetBitsets(genomes)
+
+	for _, format := range []goga.BitsetFileFormat{goga.BitsetFormatBinary, goga.BitsetFormatHex} {
+		t.Assert(goga.WriteBitsetsFile(path, bitsets, format), IsNil)
+
+		read, err := goga.ReadBitsetsFile(path)
+		t.Assert(err, IsNil)
+		t.Assert(helperBitsetStrings(read), DeepEquals, []string{"0101", "111000111"})
+	}
+
+	_, err := goga.ReadBitsetsFile(filepath.Join(t.MkDir(), "missing.txt"))
+	t.Assert(err, NotNil)
+}
+
+func helperBitsetStrings(bitsets []goga.Bitset) []string {
+	ret := make([]string, len(bitsets))
+	for i := range bitsets {
+		ret[i] = bitsets[i].String()
+	}
+	return ret
+}
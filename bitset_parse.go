@@ -1,15 +1,98 @@
 package goga
 
+import (
+	"fmt"
+	"math"
+)
+
 // BitsetParse - an interface to an object that is able
-// to parse a bitset into an array of uint64s
+// to parse a bitset into an array of uint64s, or into typed
+// values when fields and their encodings are described with SetFields.
+// Process and ProcessFields panic if the bitset is not the size of the format,
+// the E and At variants return an error instead. The At variants parse the
+// format from 'offset' within a larger bitset, so a genome made up of several
+// blocks can be parsed a block at a time
 type BitsetParse interface {
-	SetFormat([]int)
+	SetFormat([]int) error
+	SetFields([]Field) error
+	GetFields() []Field
+	GetSize() int
 	Process(*Bitset) []uint64
+	ProcessE(*Bitset) ([]uint64, error)
+	ProcessAt(*Bitset, int) ([]uint64, error)
+	ProcessFields(*Bitset) []interface{}
+	ProcessFieldsE(*Bitset) ([]interface{}, error)
+	ProcessFieldsAt(*Bitset, int) ([]interface{}, error)
+	Encode([]interface{}) (Bitset, error)
+}
+
+// FieldEncoding - describes how the bits of a Field are decoded
+type FieldEncoding int
+
+const (
+	// EncodingUnsigned decodes a little endian unsigned integer as a uint64
+	EncodingUnsigned FieldEncoding = iota
+
+	// EncodingGray decodes a little endian Gray code as a uint64, neighbouring
+	// values differ by a single bit so a mutation makes a small change
+	EncodingGray
+
+	// EncodingSigned decodes a little endian two's complement integer as an int64
+	EncodingSigned
+
+	// EncodingFixedPoint decodes a two's complement integer divided
+	// by 2^FractionBits as a float64
+	EncodingFixedPoint
+
+	// EncodingScaledFloat decodes an unsigned integer mapped evenly
+	// on to the range [Min, Max] as a float64
+	EncodingScaledFloat
+
+	// EncodingBool decodes a bool that is true if any bit is set
+	EncodingBool
+
+	// EncodingEnum decodes an unsigned integer as an index in to Values,
+	// wrapping around if there are fewer than 2^Bits values
+	EncodingEnum
+)
+
+// Field - describes a single value in a bitset
+// * Bits - the number of bits used by the value
+// * Encoding - how the bits are decoded, the zero value is EncodingUnsigned
+// * Min, Max - the range of EncodingScaledFloat, both must be finite
+// * FractionBits - the number of bits after the binary point for EncodingFixedPoint
+// * Values - the possible values of EncodingEnum
+type Field struct {
+	Bits         int
+	Encoding     FieldEncoding
+	Min          float64
+	Max          float64
+	FractionBits int
+	Values       []interface{}
+}
+
+// FieldError - the error returned when one of the fields of a BitsetParse is not
+// valid, or a value can not be encoded by its field
+// * Index - the index of the field
+// * Err - what is wrong with the field or value
+type FieldError struct {
+	Index int
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %v: %v", e.Index, e.Err)
+}
+
+// Unwrap returns what is wrong with the field or value
+func (e *FieldError) Unwrap() error {
+	return e.Err
 }
 
 type bitsetParse struct {
 	expectedBitsetSize int
 	format             []int
+	fields             []Field
 }
 
 // CreateBitsetParse returns an instance of a bitset parser
@@ -17,21 +100,68 @@ func CreateBitsetParse() BitsetParse {
 	return &bitsetParse{}
 }
 
-func (bp *bitsetParse) SetFormat(format []int) {
-	bp.expectedBitsetSize = 0
-	for _, i := range format {
-		bp.expectedBitsetSize += i
+// SetFormat sets the number of bits in each unsigned value, every width must be
+// between 1 and 64. The format is left unchanged if it is not valid
+func (bp *bitsetParse) SetFormat(format []int) error {
+	fields := make([]Field, len(format))
+	for i, numBits := range format {
+		fields[i] = Field{Bits: numBits}
+	}
+	return bp.SetFields(fields)
+}
+
+// SetFields sets the fields that the bitset is made up of, each field must be
+// between 1 and 64 bits wide. The fields are left unchanged if they are not valid
+func (bp *bitsetParse) SetFields(fields []Field) error {
+	expectedBitsetSize := 0
+	format := make([]int, len(fields))
+	for i, field := range fields {
+		if err := field.validate(); err != nil {
+			return &FieldError{Index: i, Err: err}
+		}
+		expectedBitsetSize += field.Bits
+		format[i] = field.Bits
 	}
+
+	bp.expectedBitsetSize = expectedBitsetSize
 	bp.format = format
+	bp.fields = fields
+	return nil
+}
+
+func (bp *bitsetParse) GetFields() []Field {
+	return append([]Field{}, bp.fields...)
+}
+
+func (bp *bitsetParse) GetSize() int {
+	return bp.expectedBitsetSize
 }
 
 func (bp *bitsetParse) Process(bitset *Bitset) []uint64 {
-	if bitset.GetSize() != bp.expectedBitsetSize {
+	ret, err := bp.ProcessE(bitset)
+	if err != nil {
 		panic("Input format does not match bitset size")
 	}
+	return ret
+}
 
+func (bp *bitsetParse) ProcessE(bitset *Bitset) ([]uint64, error) {
+	if bitset.GetSize() != bp.expectedBitsetSize {
+		return nil, fmt.Errorf("expected a bitset of size %v but got %v", bp.expectedBitsetSize, bitset.GetSize())
+	}
+	return bp.process(bitset, 0), nil
+}
+
+func (bp *bitsetParse) ProcessAt(bitset *Bitset, offset int) ([]uint64, error) {
+	if offset < 0 || offset+bp.expectedBitsetSize > bitset.GetSize() {
+		return nil, fmt.Errorf("%v bits at offset %v do not fit in a bitset of size %v", bp.expectedBitsetSize, offset, bitset.GetSize())
+	}
+	return bp.process(bitset, offset), nil
+}
+
+func (bp *bitsetParse) process(bitset *Bitset, offset int) []uint64 {
 	ret := make([]uint64, len(bp.format))
-	runningBits := 0
+	runningBits := offset
 	for retIndex, numBits := range bp.format {
 		ret[retIndex] = 0
 
@@ -45,3 +175,283 @@ func (bp *bitsetParse) Process(bitset *Bitset) []uint64 {
 	}
 	return ret
 }
+
+func (bp *bitsetParse) ProcessFields(bitset *Bitset) []interface{} {
+	return bp.decode(bp.Process(bitset))
+}
+
+func (bp *bitsetParse) ProcessFieldsE(bitset *Bitset) ([]interface{}, error) {
+	raw, err := bp.ProcessE(bitset)
+	if err != nil {
+		return nil, err
+	}
+	return bp.decode(raw), nil
+}
+
+func (bp *bitsetParse) ProcessFieldsAt(bitset *Bitset, offset int) ([]interface{}, error) {
+	raw, err := bp.ProcessAt(bitset, offset)
+	if err != nil {
+		return nil, err
+	}
+	return bp.decode(raw), nil
+}
+
+func (bp *bitsetParse) decode(raw []uint64) []interface{} {
+	ret := make([]interface{}, len(bp.fields))
+	for i, field := range bp.fields {
+		ret[i] = field.decode(raw[i])
+	}
+	return ret
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// maxFieldValue returns the largest unsigned value that fits in 'bits' bits
+func maxFieldValue(bits int) uint64 {
+	if bits <= 0 {
+		return 0
+	}
+	return ^uint64(0) >> uint(64-bits)
+}
+
+func grayToBinary(gray uint64) uint64 {
+	for shift := uint(1); shift < 64; shift <<= 1 {
+		gray ^= gray >> shift
+	}
+	return gray
+}
+
+func toSigned(raw uint64, bits int) int64 {
+	if bits <= 0 || bits >= 64 {
+		return int64(raw)
+	}
+	if raw&(1<<uint(bits-1)) != 0 {
+		return int64(raw) - (1 << uint(bits))
+	}
+	return int64(raw)
+}
+
+func (f *Field) validate() error {
+	if f.Bits < 1 || f.Bits > 64 {
+		return fmt.Errorf("%v bits is not between 1 and 64", f.Bits)
+	}
+	if f.Encoding == EncodingFixedPoint && (f.FractionBits < 0 || f.FractionBits > 63) {
+		return fmt.Errorf("%v fraction bits is not between 0 and 63", f.FractionBits)
+	}
+	if f.Encoding == EncodingScaledFloat && !(isFinite(f.Min) && isFinite(f.Max)) {
+		return fmt.Errorf("the range [%v, %v] is not finite", f.Min, f.Max)
+	}
+	if f.Encoding == EncodingEnum && len(f.Values) == 0 {
+		return fmt.Errorf("enum has no values")
+	}
+	return nil
+}
+
+func (f *Field) decode(raw uint64) interface{} {
+	switch f.Encoding {
+	case EncodingGray:
+		return grayToBinary(raw)
+	case EncodingSigned:
+		return toSigned(raw, f.Bits)
+	case EncodingFixedPoint:
+		return float64(toSigned(raw, f.Bits)) / float64(uint64(1)<<uint(f.FractionBits))
+	case EncodingScaledFloat:
+		maxValue := maxFieldValue(f.Bits)
+		if maxValue == 0 {
+			return f.Min
+		}
+		return f.Min + (float64(raw)/float64(maxValue))*(f.Max-f.Min)
+	case EncodingBool:
+		return raw != 0
+	case EncodingEnum:
+		if len(f.Values) == 0 {
+			return nil
+		}
+		return f.Values[raw%uint64(len(f.Values))]
+	}
+	return raw
+}
+
+// Encode is the inverse of ProcessFields, it creates a bitset from a value for
+// each field. Unsigned, Gray and Signed fields accept any integer type,
+// FixedPoint and ScaledFloat fields accept floats or integers, Bool fields a bool
+// and Enum fields one of the field's Values. Values that can not be represented
+// by their field are an error, ScaledFloat values are rounded to the nearest
+// value that can be represented. The error of a value that can not be encoded is a *FieldError
+func (bp *bitsetParse) Encode(values []interface{}) (Bitset, error) {
+	if len(values) != len(bp.fields) {
+		return Bitset{}, fmt.Errorf("expected %v values but got %v", len(bp.fields), len(values))
+	}
+
+	ret := Bitset{}
+	ret.Create(bp.expectedBitsetSize)
+	runningBits := 0
+	for i, field := range bp.fields {
+		raw, err := field.encode(values[i])
+		if err != nil {
+			return Bitset{}, &FieldError{Index: i, Err: err}
+		}
+
+		for bit := 0; bit < field.Bits; bit++ {
+			ret.Set(runningBits+bit, int((raw>>uint(bit))&1))
+		}
+		runningBits += field.Bits
+	}
+	return ret, nil
+}
+
+func binaryToGray(binary uint64) uint64 {
+	return binary ^ (binary >> 1)
+}
+
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), v <= math.MaxInt64
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	}
+	return 0, false
+}
+
+func toUint64(value interface{}) (uint64, bool) {
+	switch v := value.(type) {
+	case uint:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	}
+	i, ok := toInt64(value)
+	return uint64(i), ok && i >= 0
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	i, ok := toInt64(value)
+	return float64(i), ok
+}
+
+func (f *Field) encodeSigned(value int64) (uint64, error) {
+	if f.Bits < 64 {
+		limit := int64(1) << uint(f.Bits-1)
+		if value < -limit || value >= limit {
+			return 0, fmt.Errorf("%v does not fit in %v signed bits", value, f.Bits)
+		}
+	}
+	return uint64(value) & maxFieldValue(f.Bits), nil
+}
+
+func (f *Field) encode(value interface{}) (uint64, error) {
+	switch f.Encoding {
+	case EncodingUnsigned, EncodingGray:
+		raw, ok := toUint64(value)
+		if !ok {
+			return 0, fmt.Errorf("expected a non-negative integer but got %v", value)
+		}
+		if raw > maxFieldValue(f.Bits) {
+			return 0, fmt.Errorf("%v does not fit in %v bits", raw, f.Bits)
+		}
+		if f.Encoding == EncodingGray {
+			return binaryToGray(raw), nil
+		}
+		return raw, nil
+
+	case EncodingSigned:
+		signed, ok := toInt64(value)
+		if !ok {
+			return 0, fmt.Errorf("expected an integer but got %v", value)
+		}
+		return f.encodeSigned(signed)
+
+	case EncodingFixedPoint:
+		v, ok := toFloat64(value)
+		if !ok {
+			return 0, fmt.Errorf("expected a number but got %v", value)
+		}
+		scaled := v * float64(uint64(1)<<uint(f.FractionBits))
+		if scaled != math.Trunc(scaled) {
+			return 0, fmt.Errorf("%v can not be represented with %v fraction bits", v, f.FractionBits)
+		}
+
+		// Check the range before converting as converting a float64 outside of
+		// the range of an int64 is implementation defined
+		limit := math.Exp2(float64(f.Bits - 1))
+		if f.Bits > 53 && scaled == limit {
+			// The largest value can't be represented by a float64 so it is decoded
+			// as, and rounded up to, 'limit'
+			return maxFieldValue(f.Bits - 1), nil
+		}
+		if scaled < -limit || scaled >= limit {
+			return 0, fmt.Errorf("%v does not fit in %v signed bits", scaled, f.Bits)
+		}
+		return f.encodeSigned(int64(scaled))
+
+	case EncodingScaledFloat:
+		v, ok := toFloat64(value)
+		if !ok {
+			return 0, fmt.Errorf("expected a number but got %v", value)
+		}
+		if math.IsNaN(v) || v < math.Min(f.Min, f.Max) || v > math.Max(f.Min, f.Max) {
+			return 0, fmt.Errorf("%v is outside of the range [%v, %v]", v, f.Min, f.Max)
+		}
+		maxValue := maxFieldValue(f.Bits)
+		if maxValue == 0 || f.Max == f.Min {
+			return 0, nil
+		}
+		// float64(maxValue) rounds up beyond the largest uint64 for wide fields,
+		// so clamp before converting
+		scaled := math.Round(((v - f.Min) / (f.Max - f.Min)) * float64(maxValue))
+		if scaled >= float64(maxValue) {
+			return maxValue, nil
+		}
+		if scaled <= 0 {
+			return 0, nil
+		}
+		return uint64(scaled), nil
+
+	case EncodingBool:
+		b, ok := value.(bool)
+		if !ok {
+			return 0, fmt.Errorf("expected a bool but got %v", value)
+		}
+		if b {
+			return 1, nil
+		}
+		return 0, nil
+
+	case EncodingEnum:
+		for i, v := range f.Values {
+			if v == value {
+				if uint64(i) > maxFieldValue(f.Bits) {
+					break
+				}
+				return uint64(i), nil
+			}
+		}
+		return 0, fmt.Errorf("%v is not one of the field's values", value)
+	}
+	return 0, fmt.Errorf("unknown encoding %v", f.Encoding)
+}
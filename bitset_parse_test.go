@@ -1,6 +1,8 @@
 package goga_test
 
 import (
+	"errors"
+	"math"
 	"math/rand"
 
 	"github.com/tomcraven/goga"
@@ -42,12 +44,12 @@ func (s *BitsetParseSuite) TestShouldPanicWithMismatchedFormatAndBitsetSize(t *C
 
 func (s *BitsetParseSuite) TestShouldNotPanicWithCorrectFormatAndBitsetSize(t *C) {
 	inputFormat := []int{
-		rand.Intn(10),
-		rand.Intn(10),
-		rand.Intn(10),
-		rand.Intn(10),
-		rand.Intn(10),
-		rand.Intn(10),
+		rand.Intn(10) + 1,
+		rand.Intn(10) + 1,
+		rand.Intn(10) + 1,
+		rand.Intn(10) + 1,
+		rand.Intn(10) + 1,
+		rand.Intn(10) + 1,
 	}
 	s.bp.SetFormat(inputFormat)
 
@@ -62,6 +64,55 @@ func (s *BitsetParseSuite) TestShouldNotPanicWithCorrectFormatAndBitsetSize(t *C
 	s.bp.Process(&inputBitset)
 }
 
+func (s *BitsetParseSuite) TestShouldRejectInvalidFormats(t *C) {
+	t.Assert(s.bp.SetFormat([]int{1, 64}), IsNil)
+	t.Assert(s.bp.SetFormat([]int{1, 0}), ErrorMatches, "field 1: 0 bits is not between 1 and 64")
+	t.Assert(s.bp.SetFormat([]int{-1}), ErrorMatches, "field 0: -1 bits is not between 1 and 64")
+	t.Assert(s.bp.SetFormat([]int{65}), ErrorMatches, "field 0: 65 bits is not between 1 and 64")
+	t.Assert(s.bp.SetFields([]goga.Field{{Bits: 2, Encoding: goga.EncodingEnum}}), ErrorMatches, "field 0: enum has no values")
+	t.Assert(s.bp.SetFields([]goga.Field{{Bits: 2, Encoding: goga.EncodingScaledFloat, Min: math.NaN(), Max: 1}}), ErrorMatches,
+		`field 0: the range \[NaN, 1\] is not finite`)
+	t.Assert(s.bp.SetFields([]goga.Field{{Bits: 2, Encoding: goga.EncodingScaledFloat, Min: 0, Max: math.Inf(1)}}), ErrorMatches,
+		`field 0: the range \[0, \+Inf\] is not finite`)
+
+	// The last valid format is kept
+	t.Assert(s.bp.GetSize(), Equals, 65)
+}
+
+func (s *BitsetParseSuite) TestShouldReturnErrorWithMismatchedFormatAndBitsetSize(t *C) {
+	s.bp.SetFormat([]int{2, 3})
+
+	ret, err := s.bp.ProcessE(helperBitset(t, "0110"))
+	t.Assert(ret, IsNil)
+	t.Assert(err, ErrorMatches, "expected a bitset of size 5 but got 4")
+
+	fields, err := s.bp.ProcessFieldsE(helperBitset(t, "011011"))
+	t.Assert(fields, IsNil)
+	t.Assert(err, ErrorMatches, "expected a bitset of size 5 but got 6")
+
+	ret, err = s.bp.ProcessE(helperBitset(t, "01110"))
+	t.Assert(err, IsNil)
+	t.Assert(ret, DeepEquals, []uint64{2, 3})
+}
+
+func (s *BitsetParseSuite) TestShouldProcessAtOffset(t *C) {
+	s.bp.SetFields([]goga.Field{{Bits: 2}, {Bits: 3, Encoding: goga.EncodingSigned}})
+	b := helperBitset(t, "1110111101")
+
+	ret, err := s.bp.ProcessAt(b, 0)
+	t.Assert(err, IsNil)
+	t.Assert(ret, DeepEquals, []uint64{3, 5})
+
+	fields, err := s.bp.ProcessFieldsAt(b, 5)
+	t.Assert(err, IsNil)
+	t.Assert(fields, DeepEquals, []interface{}{uint64(3), int64(-3)})
+
+	_, err = s.bp.ProcessAt(b, 6)
+	t.Assert(err, ErrorMatches, "5 bits at offset 6 do not fit in a bitset of size 10")
+	_, err = s.bp.ProcessFieldsAt(b, -1)
+	t.Assert(err, NotNil)
+}
+
 func (s *BitsetParseSuite) TestShouldProcessSingleFormat(t *C) {
 	inputFormat := []int{
 		16,
@@ -107,3 +158,250 @@ func (s *BitsetParseSuite) TestShouldProcessMultipleFormat(t *C) {
 	}
 	t.Assert(s.bp.Process(&inputBitset), DeepEquals, []uint64{0, 255})
 }
+
+func helperBitset(t *C, bits string) *goga.Bitset {
+	b, err := goga.ParseBitset(bits)
+	t.Assert(err, IsNil)
+	return &b
+}
+
+func (s *BitsetParseSuite) TestShouldProcessUnsignedFieldsByDefault(t *C) {
+	s.bp.SetFields([]goga.Field{{Bits: 3}, {Bits: 2}})
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "11001")), DeepEquals, []interface{}{uint64(3), uint64(2)})
+	t.Assert(s.bp.Process(helperBitset(t, "11001")), DeepEquals, []uint64{3, 2})
+}
+
+func (s *BitsetParseSuite) TestShouldProcessGrayFields(t *C) {
+	s.bp.SetFields([]goga.Field{{Bits: 3, Encoding: goga.EncodingGray}})
+
+	// Gray codes for 0..7 are 000, 001, 011, 010, 110, 111, 101, 100 (most significant bit first)
+	grayCodes := []string{"000", "100", "110", "010", "011", "111", "101", "001"}
+	for value, gray := range grayCodes {
+		t.Assert(s.bp.ProcessFields(helperBitset(t, gray)), DeepEquals, []interface{}{uint64(value)})
+	}
+}
+
+func (s *BitsetParseSuite) TestShouldProcessSignedFields(t *C) {
+	s.bp.SetFields([]goga.Field{{Bits: 4, Encoding: goga.EncodingSigned}})
+
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "0000")), DeepEquals, []interface{}{int64(0)})
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "1110")), DeepEquals, []interface{}{int64(7)})
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "0001")), DeepEquals, []interface{}{int64(-8)})
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "1111")), DeepEquals, []interface{}{int64(-1)})
+
+	s.bp.SetFields([]goga.Field{{Bits: 64, Encoding: goga.EncodingSigned}})
+	allOnes := goga.Bitset{}
+	allOnes.Create(64)
+	allOnes.SetAll(1)
+	t.Assert(s.bp.ProcessFields(&allOnes), DeepEquals, []interface{}{int64(-1)})
+}
+
+func (s *BitsetParseSuite) TestShouldProcessFixedPointFields(t *C) {
+	s.bp.SetFields([]goga.Field{{Bits: 6, Encoding: goga.EncodingFixedPoint, FractionBits: 2}})
+
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "101000")), DeepEquals, []interface{}{1.25})
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "111111")), DeepEquals, []interface{}{-0.25})
+}
+
+func (s *BitsetParseSuite) TestShouldProcessScaledFloatFields(t *C) {
+	s.bp.SetFields([]goga.Field{{Bits: 2, Encoding: goga.EncodingScaledFloat, Min: -1, Max: 2}})
+
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "00")), DeepEquals, []interface{}{-1.0})
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "10")), DeepEquals, []interface{}{0.0})
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "01")), DeepEquals, []interface{}{1.0})
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "11")), DeepEquals, []interface{}{2.0})
+}
+
+func (s *BitsetParseSuite) TestShouldProcessBoolAndEnumFields(t *C) {
+	s.bp.SetFields([]goga.Field{
+		{Bits: 1, Encoding: goga.EncodingBool},
+		{Bits: 2, Encoding: goga.EncodingEnum, Values: []interface{}{"red", "green", "blue"}},
+	})
+
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "000")), DeepEquals, []interface{}{false, "red"})
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "101")), DeepEquals, []interface{}{true, "blue"})
+
+	// Out of range values wrap around
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "111")), DeepEquals, []interface{}{true, "red"})
+}
+
+func (s *BitsetParseSuite) TestShouldProcessMixedFields(t *C) {
+	s.bp.SetFields([]goga.Field{
+		{Bits: 2},
+		{Bits: 2, Encoding: goga.EncodingSigned},
+		{Bits: 1, Encoding: goga.EncodingBool},
+	})
+	t.Assert(s.bp.ProcessFields(helperBitset(t, "10011")), DeepEquals, []interface{}{uint64(1), int64(-2), true})
+	t.Assert(s.bp.Process(helperBitset(t, "10011")), DeepEquals, []uint64{1, 2, 1})
+}
+
+func (s *BitsetParseSuite) TestShouldEncodeFields(t *C) {
+	s.bp.SetFields([]goga.Field{
+		{Bits: 2},
+		{Bits: 2, Encoding: goga.EncodingSigned},
+		{Bits: 1, Encoding: goga.EncodingBool},
+		{Bits: 3, Encoding: goga.EncodingGray},
+		{Bits: 2, Encoding: goga.EncodingEnum, Values: []interface{}{"red", "green", "blue"}},
+	})
+
+	b, err := s.bp.Encode([]interface{}{1, int64(-2), true, uint8(4), "green"})
+	t.Assert(err, IsNil)
+	t.Assert(b.String(), Equals, "1001101110")
+}
+
+func (s *BitsetParseSuite) TestShouldEncodeFloatFields(t *C) {
+	s.bp.SetFields([]goga.Field{
+		{Bits: 6, Encoding: goga.EncodingFixedPoint, FractionBits: 2},
+		{Bits: 2, Encoding: goga.EncodingScaledFloat, Min: -1, Max: 2},
+	})
+
+	b, err := s.bp.Encode([]interface{}{-0.25, 1})
+	t.Assert(err, IsNil)
+	t.Assert(b.String(), Equals, "11111101")
+
+	// Scaled floats are rounded to the nearest representable value
+	b, err = s.bp.Encode([]interface{}{1.25, 0.4})
+	t.Assert(err, IsNil)
+	t.Assert(s.bp.ProcessFields(&b), DeepEquals, []interface{}{1.25, 0.0})
+}
+
+func (s *BitsetParseSuite) TestShouldNotEncodeInvalidValues(t *C) {
+	s.bp.SetFields([]goga.Field{
+		{Bits: 2},
+		{Bits: 2, Encoding: goga.EncodingSigned},
+		{Bits: 4, Encoding: goga.EncodingFixedPoint, FractionBits: 1},
+		{Bits: 4, Encoding: goga.EncodingScaledFloat, Min: 0, Max: 1},
+		{Bits: 1, Encoding: goga.EncodingBool},
+		{Bits: 1, Encoding: goga.EncodingEnum, Values: []interface{}{"a", "b", "c"}},
+	})
+	valid := []interface{}{3, -2, 1.5, 0.5, false, "b"}
+	_, err := s.bp.Encode(valid)
+	t.Assert(err, IsNil)
+
+	_, err = s.bp.Encode(valid[1:])
+	t.Assert(err, ErrorMatches, "expected 6 values but got 5")
+
+	invalid := []struct {
+		index int
+		value interface{}
+		err   string
+	}{
+		{0, 4, "field 0: 4 does not fit in 2 bits"},
+		{0, -1, "field 0: expected a non-negative integer but got -1"},
+		{0, "1", "field 0: expected a non-negative integer but got 1"},
+		{1, 2, "field 1: 2 does not fit in 2 signed bits"},
+		{1, -3, "field 1: -3 does not fit in 2 signed bits"},
+		{2, 1.25, "field 2: 1.25 can not be represented with 1 fraction bits"},
+		{2, 4, "field 2: 8 does not fit in 4 signed bits"},
+		{3, 1.5, `field 3: 1.5 is outside of the range \[0, 1\]`},
+		{3, math.NaN(), `field 3: NaN is outside of the range \[0, 1\]`},
+		{3, math.Inf(1), `field 3: \+Inf is outside of the range \[0, 1\]`},
+		{4, 1, "field 4: expected a bool but got 1"},
+		{5, "c", "field 5: c is not one of the field's values"},
+		{5, "d", "field 5: d is not one of the field's values"},
+	}
+	for _, test := range invalid {
+		values := append([]interface{}{}, valid...)
+		values[test.index] = test.value
+		_, err := s.bp.Encode(values)
+		t.Assert(err, ErrorMatches, test.err)
+
+		var fieldErr *goga.FieldError
+		t.Assert(errors.As(err, &fieldErr), IsTrue)
+		t.Assert(fieldErr.Index, Equals, test.index)
+	}
+}
+
+func (s *BitsetParseSuite) TestShouldEncodeTheLimitsOf64BitFields(t *C) {
+	s.bp.SetFields([]goga.Field{
+		{Bits: 64, Encoding: goga.EncodingScaledFloat, Min: -2.5, Max: 7},
+		{Bits: 64, Encoding: goga.EncodingFixedPoint, FractionBits: 8},
+	})
+
+	zeros, ones := goga.Bitset{}, goga.Bitset{}
+	zeros.Create(64)
+	ones.Create(64)
+	ones.SetAll(1)
+	signBit := goga.Bitset{}
+	signBit.Create(64)
+	signBit.Set(63, 1)
+	largest := ones.CreateCopy()
+	largest.Set(63, 0)
+
+	for _, test := range []struct {
+		values []interface{}
+		bits   []goga.Bitset
+	}{
+		// Min and the most negative fixed point value
+		{[]interface{}{-2.5, -math.Exp2(55)}, []goga.Bitset{zeros, signBit}},
+		// Max and the largest fixed point value, which is rounded as a float64
+		{[]interface{}{7.0, math.Exp2(55)}, []goga.Bitset{ones, largest}},
+	} {
+		encoded, err := s.bp.Encode(test.values)
+		t.Assert(err, IsNil)
+		t.Assert(encoded.String(), Equals, test.bits[0].String()+test.bits[1].String())
+		t.Assert(s.bp.ProcessFields(&encoded), DeepEquals, test.values)
+	}
+
+	_, err := s.bp.Encode([]interface{}{7.0, math.Exp2(56)})
+	t.Assert(err, ErrorMatches, "field 1: 1.8446744073709552e\\+19 does not fit in 64 signed bits")
+	_, err = s.bp.Encode([]interface{}{7.0, -math.Exp2(56)})
+	t.Assert(err, ErrorMatches, "field 1: -1.8446744073709552e\\+19 does not fit in 64 signed bits")
+}
+
+func helperRandomBitset(size int) goga.Bitset {
+	b := goga.Bitset{}
+	b.Create(size)
+	for i := 0; i < size; i++ {
+		b.Set(i, rand.Intn(2))
+	}
+	return b
+}
+
+func (s *BitsetParseSuite) TestShouldRoundTripBitsetsThroughValues(t *C) {
+	s.bp.SetFields([]goga.Field{
+		{Bits: 7},
+		{Bits: 64},
+		{Bits: 9, Encoding: goga.EncodingGray},
+		{Bits: 5, Encoding: goga.EncodingSigned},
+		{Bits: 64, Encoding: goga.EncodingSigned},
+		{Bits: 10, Encoding: goga.EncodingFixedPoint, FractionBits: 4},
+		{Bits: 12, Encoding: goga.EncodingScaledFloat, Min: -3.5, Max: 11},
+		{Bits: 1, Encoding: goga.EncodingBool},
+		{Bits: 2, Encoding: goga.EncodingEnum, Values: []interface{}{"a", "b", 3, 4.5}},
+	})
+
+	for i := 0; i < 1000; i++ {
+		original := helperRandomBitset(7 + 64 + 9 + 5 + 64 + 10 + 12 + 1 + 2)
+		values := s.bp.ProcessFields(&original)
+
+		encoded, err := s.bp.Encode(values)
+		t.Assert(err, IsNil)
+		t.Assert(encoded.String(), Equals, original.String())
+		t.Assert(s.bp.ProcessFields(&encoded), DeepEquals, values)
+	}
+}
+
+func (s *BitsetParseSuite) TestShouldRoundTripValuesThroughBitsets(t *C) {
+	s.bp.SetFields([]goga.Field{
+		{Bits: 16},
+		{Bits: 16, Encoding: goga.EncodingGray},
+		{Bits: 16, Encoding: goga.EncodingSigned},
+		{Bits: 16, Encoding: goga.EncodingFixedPoint, FractionBits: 8},
+		{Bits: 1, Encoding: goga.EncodingBool},
+	})
+
+	for i := 0; i < 1000; i++ {
+		values := []interface{}{
+			uint64(rand.Intn(1 << 16)),
+			uint64(rand.Intn(1 << 16)),
+			int64(rand.Intn(1<<16) - (1 << 15)),
+			float64(rand.Intn(1<<16)-(1<<15)) / 256,
+			rand.Intn(2) == 1,
+		}
+
+		encoded, err := s.bp.Encode(values)
+		t.Assert(err, IsNil)
+		t.Assert(s.bp.ProcessFields(&encoded), DeepEquals, values)
+	}
+}
@@ -100,3 +100,22 @@ func (s *BitsetSuite) TestShouldGetAll(t *C) {
 		t.Assert(bits[i], Equals, 1)
 	}
 }
+
+func (s *BitsetSuite) TestShouldConvertToString(t *C) {
+	t.Assert(s.bitset.String(), Equals, "")
+
+	s.bitset.Create(5)
+	s.bitset.Set(1, 1)
+	s.bitset.Set(4, 1)
+	t.Assert(s.bitset.String(), Equals, "01001")
+}
+
+func (s *BitsetSuite) TestShouldParseString(t *C) {
+	b, err := goga.ParseBitset("01001")
+	t.Assert(err, IsNil)
+	t.Assert(b.GetAll(), DeepEquals, []int{0, 1, 0, 0, 1})
+	t.Assert(b.String(), Equals, "01001")
+
+	_, err = goga.ParseBitset("01x01")
+	t.Assert(err, NotNil)
+}
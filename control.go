@@ -0,0 +1,202 @@
+package goga
+
+import "sync"
+
+// runControl - the pause, step and stop requests made of a GeneticAlgorithm from other goroutines
+// * paused - the run waits at the end of each generation while this is set
+// * stopped - the run ends at the end of its current generation
+// * steps - the number of generations left to run before pausing, 0 to run without pausing
+// * running - a call to Simulate is in progress
+// * waiting - the run is paused at the end of a generation
+// * completed - the number of generations completed by the current run
+type runControl struct {
+	mutex     sync.Mutex
+	changed   *sync.Cond
+	paused    bool
+	stopped   bool
+	steps     int
+	running   bool
+	waiting   bool
+	completed int
+}
+
+func newRunControl() *runControl {
+	c := &runControl{}
+	c.changed = sync.NewCond(&c.mutex)
+	return c
+}
+
+// runControl returns the run's control, creating it the first time it is needed
+// so that a GeneticAlgorithm that was not made by NewGeneticAlgorithm can be controlled
+func (ga *GeneticAlgorithm) runControl() *runControl {
+	ga.controlOnce.Do(func() {
+		ga.control = newRunControl()
+	})
+	return ga.control
+}
+
+// Pause makes a run wait at the end of its current generation until it is resumed,
+// stepped or stopped. A run that is started while paused waits after its first generation.
+// It is safe to call from any goroutine
+func (ga *GeneticAlgorithm) Pause() {
+	c := ga.runControl()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.paused = true
+	c.steps = 0
+}
+
+// Resume continues a paused run. It is safe to call from any goroutine
+func (ga *GeneticAlgorithm) Resume() {
+	c := ga.runControl()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.paused = false
+	c.steps = 0
+	c.changed.Broadcast()
+}
+
+// Step runs exactly 'n' more generations and then pauses, whether the run is
+// paused or not. It is safe to call from any goroutine
+func (ga *GeneticAlgorithm) Step(n int) {
+	if n <= 0 {
+		return
+	}
+
+	c := ga.runControl()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.paused = false
+	c.steps = n
+	c.changed.Broadcast()
+}
+
+// Stop ends the current run at the end of its current generation, even if it is
+// paused, it does nothing if no run is in progress. The termination reason is
+// "stopped". It is safe to call from any goroutine
+func (ga *GeneticAlgorithm) Stop() {
+	c := ga.runControl()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.running {
+		return
+	}
+	c.stopped = true
+	c.changed.Broadcast()
+}
+
+// Paused returns true if the run has been paused, it may still be finishing its current generation
+func (ga *GeneticAlgorithm) Paused() bool {
+	c := ga.runControl()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.paused
+}
+
+// Stopped returns true if Stop has been called and the run it ends has not finished yet
+func (ga *GeneticAlgorithm) Stopped() bool {
+	c := ga.runControl()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.stopped
+}
+
+// RunGenerations runs 'n' generations and returns, leaving the run paused so that
+// a later call carries on from where it left off, for driving evolution a few
+// generations at a time from notebooks and interactive tools.
+// The first call starts a run on a new goroutine as Simulate would, counting the
+// simulation of the initial population as its first generation. It returns false,
+// after fewer than 'n' generations, if the run ends, in which case the next call
+// starts a new run. Callers must call Stop once they no longer want a run that is
+// still in progress, otherwise its goroutine is left waiting for the next call forever
+func (ga *GeneticAlgorithm) RunGenerations(n int) bool {
+	c := ga.runControl()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if n <= 0 {
+		return c.running
+	}
+	if !c.running {
+		// The run is begun here, rather than on its goroutine, so calls made
+		// before the goroutine starts see the new run
+		c.beginRunLocked()
+		go ga.simulate()
+	}
+
+	target := c.completed + n
+	c.paused = false
+	c.steps = n
+	c.changed.Broadcast()
+	for c.running && !(c.waiting && c.completed >= target) {
+		c.changed.Wait()
+	}
+	return c.running
+}
+
+// beginRun is called as Simulate starts
+func (c *runControl) beginRun() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.beginRunLocked()
+}
+
+// beginRunLocked marks a run as in progress, the mutex must be held
+func (c *runControl) beginRunLocked() {
+	c.running = true
+	c.completed = 0
+}
+
+// endRun is called as Simulate returns, a Pause, Step or Stop only applies to the
+// run that was in progress
+func (c *runControl) endRun() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.running = false
+	c.waiting = false
+	c.stopped = false
+	c.paused = false
+	c.steps = 0
+	c.changed.Broadcast()
+}
+
+// endGeneration is called at the end of each generation the run carries on from,
+// it waits while the run is paused and returns true if it has been stopped
+func (c *runControl) endGeneration() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.completed++
+	if c.steps > 0 {
+		c.steps--
+		if c.steps == 0 {
+			c.paused = true
+		}
+	}
+
+	c.waiting = true
+	c.changed.Broadcast()
+	for c.paused && !c.stopped {
+		c.changed.Wait()
+	}
+	c.waiting = false
+	return c.stopped
+}
+
+// checkControl is called at the end of each generation, it waits while the run is
+// paused and returns true if it has been stopped
+func (ga *GeneticAlgorithm) checkControl() bool {
+	if !ga.runControl().endGeneration() {
+		return false
+	}
+
+	ga.stateMutex.Lock()
+	ga.terminationReason = "stopped"
+	ga.stateMutex.Unlock()
+	return true
+}
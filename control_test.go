@@ -0,0 +1,210 @@
+package goga_test
+
+import (
+	"time"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type ControlSuite struct {
+}
+
+var _ = Suite(&ControlSuite{})
+
+func helperControlledAlgorithm() *goga.GeneticAlgorithm {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorCountOnes{}
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(16)
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.UniformCrossover},
+		{P: 1, F: goga.Mutate},
+	})
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: goga.Roulette},
+	})
+	genAlgo.Init(10, 2)
+	return &genAlgo
+}
+
+// helperSimulateInBackground runs 'genAlgo' on another goroutine, the returned channel is closed when it returns
+func helperSimulateInBackground(genAlgo *goga.GeneticAlgorithm) chan bool {
+	done := make(chan bool)
+	go func() {
+		genAlgo.Simulate()
+		close(done)
+	}()
+	return done
+}
+
+func helperWaitForGeneration(genAlgo *goga.GeneticAlgorithm, generation int) {
+	for genAlgo.GetRunState().Generation < generation {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func helperWaitForTermination(genAlgo *goga.GeneticAlgorithm, reason string) {
+	for genAlgo.GetTerminationReason() != reason {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func helperWaitUntilDone(t *C, done chan bool) {
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the run did not end")
+	}
+}
+
+func (s *ControlSuite) TestShouldPauseAndResume(t *C) {
+	genAlgo := helperControlledAlgorithm()
+	genAlgo.Terminator = goga.MaxGenerations(20)
+
+	genAlgo.Pause()
+	t.Assert(genAlgo.Paused(), IsTrue)
+	done := helperSimulateInBackground(genAlgo)
+
+	// A run started while paused waits after its first generation
+	helperWaitForGeneration(genAlgo, 1)
+	time.Sleep(20 * time.Millisecond)
+	t.Assert(genAlgo.GetRunState().Generation, Equals, 1)
+
+	genAlgo.Resume()
+	t.Assert(genAlgo.Paused(), IsFalse)
+	helperWaitUntilDone(t, done)
+	t.Assert(genAlgo.GetRunState().Generation, Equals, 20)
+	t.Assert(genAlgo.GetTerminationReason(), Equals, "reached 20 generations")
+}
+
+func (s *ControlSuite) TestShouldStepExactlyNGenerations(t *C) {
+	genAlgo := helperControlledAlgorithm()
+	genAlgo.Pause()
+	done := helperSimulateInBackground(genAlgo)
+	helperWaitForGeneration(genAlgo, 1)
+
+	for _, n := range []int{1, 3, 5} {
+		before := genAlgo.GetRunState().Generation
+		genAlgo.Step(n)
+		helperWaitForGeneration(genAlgo, before+n)
+		time.Sleep(20 * time.Millisecond)
+		t.Assert(genAlgo.GetRunState().Generation, Equals, before+n)
+		t.Assert(genAlgo.Paused(), IsTrue)
+	}
+
+	genAlgo.Stop()
+	helperWaitUntilDone(t, done)
+	t.Assert(genAlgo.GetRunState().Generation, Equals, 10)
+}
+
+func (s *ControlSuite) TestShouldStop(t *C) {
+	genAlgo := helperControlledAlgorithm()
+	done := helperSimulateInBackground(genAlgo)
+	helperWaitForGeneration(genAlgo, 5)
+
+	genAlgo.Stop()
+	helperWaitUntilDone(t, done)
+	t.Assert(genAlgo.GetTerminationReason(), Equals, "stopped")
+
+	// The stop only applied to that run
+	t.Assert(genAlgo.Stopped(), IsFalse)
+	genAlgo.Terminator = goga.MaxGenerations(3)
+	genAlgo.Simulate()
+	t.Assert(genAlgo.GetTerminationReason(), Equals, "reached 3 generations")
+}
+
+func (s *ControlSuite) TestShouldStopAPausedRun(t *C) {
+	genAlgo := helperControlledAlgorithm()
+	genAlgo.Pause()
+	done := helperSimulateInBackground(genAlgo)
+	helperWaitForGeneration(genAlgo, 1)
+
+	genAlgo.Stop()
+	t.Assert(genAlgo.Stopped(), IsTrue)
+	helperWaitUntilDone(t, done)
+	t.Assert(genAlgo.GetRunState().Generation, Equals, 1)
+	t.Assert(genAlgo.GetTerminationReason(), Equals, "stopped")
+}
+
+func (s *ControlSuite) TestShouldRunGenerationsIncrementally(t *C) {
+	genAlgo := helperControlledAlgorithm()
+	genAlgo.Terminator = goga.MaxGenerations(10)
+
+	t.Assert(genAlgo.RunGenerations(0), IsFalse)
+	t.Assert(genAlgo.RunGenerations(1), IsTrue)
+	t.Assert(genAlgo.GetRunState().Generation, Equals, 1)
+	t.Assert(genAlgo.Paused(), IsTrue)
+
+	// The population can be looked at between calls
+	t.Assert(genAlgo.GetPopulation(), HasLen, 10)
+	t.Assert(genAlgo.RunGenerations(4), IsTrue)
+	t.Assert(genAlgo.GetRunState().Generation, Equals, 5)
+
+	// The run ends part way through
+	t.Assert(genAlgo.RunGenerations(10), IsFalse)
+	t.Assert(genAlgo.GetRunState().Generation, Equals, 10)
+
+	// So the next call starts a new run, which can be stopped
+	t.Assert(genAlgo.RunGenerations(2), IsTrue)
+	t.Assert(genAlgo.GetRunState().Generation, Equals, 2)
+	genAlgo.Stop()
+	helperWaitForTermination(genAlgo, "stopped")
+	t.Assert(genAlgo.GetRunState().Generation, Equals, 2)
+}
+
+func (s *ControlSuite) TestShouldRunGenerationsInSteadyState(t *C) {
+	genAlgo := helperControlledAlgorithm()
+	genAlgo.SteadyState = true
+
+	t.Assert(genAlgo.RunGenerations(3), IsTrue)
+	t.Assert(genAlgo.GetRunState().Generation, Equals, 3)
+	t.Assert(genAlgo.GetRunState().Evaluations, Equals, 30)
+	genAlgo.Stop()
+	helperWaitForTermination(genAlgo, "stopped")
+}
+
+func (s *ControlSuite) TestShouldControlAStructLiteral(t *C) {
+	// Only the fields a GeneticAlgorithm has always needed are set
+	genAlgo := goga.GeneticAlgorithm{
+		EliteConsumer: &goga.NullEliteConsumer{},
+		Mater:         &goga.NullMater{},
+		Simulator:     &MySimulatorCountOnes{},
+		Selector:      &goga.NullSelector{},
+		BitsetCreate:  goga.NewRandomBitsetCreate(8),
+		Terminator:    goga.MaxGenerations(3),
+	}
+	genAlgo.Init(4, 1)
+
+	t.Assert(genAlgo.Paused(), IsFalse)
+	t.Assert(genAlgo.Simulate(), IsTrue)
+	t.Assert(genAlgo.GetRunState().Generation, Equals, 3)
+	t.Assert(genAlgo.GetTerminationReason(), Equals, "reached 3 generations")
+}
+
+func (s *ControlSuite) TestShouldSimulateAfterRunGenerationsIsStopped(t *C) {
+	genAlgo := helperControlledAlgorithm()
+	t.Assert(genAlgo.RunGenerations(2), IsTrue)
+	genAlgo.Stop()
+	helperWaitForTermination(genAlgo, "stopped")
+	for genAlgo.Stopped() {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The run that was left paused by RunGenerations doesn't pause the next one
+	genAlgo.Terminator = goga.MaxGenerations(3)
+	helperWaitUntilDone(t, helperSimulateInBackground(genAlgo))
+	t.Assert(genAlgo.Paused(), IsFalse)
+	t.Assert(genAlgo.GetTerminationReason(), Equals, "reached 3 generations")
+}
+
+func (s *ControlSuite) TestShouldNotStopWithoutARun(t *C) {
+	genAlgo := helperControlledAlgorithm()
+	genAlgo.Terminator = goga.MaxGenerations(3)
+	genAlgo.Simulate()
+
+	genAlgo.Stop()
+	t.Assert(genAlgo.Stopped(), IsFalse)
+	genAlgo.Simulate()
+	t.Assert(genAlgo.GetRunState().Generation, Equals, 3)
+	t.Assert(genAlgo.GetTerminationReason(), Equals, "reached 3 generations")
+}
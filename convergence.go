@@ -0,0 +1,197 @@
+package goga
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	defaultReseedFraction           = 0.5
+	defaultHypermutationRate        = 0.1
+	defaultHypermutationGenerations = 5
+)
+
+// ConvergenceResponse - what a GeneticAlgorithm does when its population converges
+type ConvergenceResponse int
+
+const (
+	// Reseed replaces a fraction of the next generation's offspring with genomes
+	// created by GenomeCreate, or BitsetCreate if it is not set
+	Reseed ConvergenceResponse = iota
+	// Hypermutate flips every bit of the offspring with a raised probability
+	// for a number of generations
+	Hypermutate
+	// Restart replaces the whole of the next generation with created genomes,
+	// the hall of fame is kept so the best genomes found so far are not lost
+	Restart
+)
+
+// Convergence -
+// Detects a population that has converged prematurely and how to respond to it
+// * MinDiversity - the population has converged when its Diversity is below this, 0 to not check
+// * Stagnation - the population has converged when the elite's fitness has not improved
+// for this many generations, 0 to not check
+// * Response - what to do when the population converges
+// * ReseedFraction - the fraction of offspring replaced by Reseed, 0.5 if not set
+// * HypermutationRate - the probability of each bit flipping when hypermutating, 0.1 if not set
+// * HypermutationGenerations - the number of generations Hypermutate lasts, 5 if not set
+//
+// Convergence is not checked again until the response has finished, and stagnation
+// is only counted from the most recent response
+type Convergence struct {
+	MinDiversity             float64
+	Stagnation               int
+	Response                 ConvergenceResponse
+	ReseedFraction           float64
+	HypermutationRate        float64
+	HypermutationGenerations int
+}
+
+// Diversity returns how varied the bits of 'population' are, between 0 when
+// every genome has the same bits and 1 when each bit is set in half of the genomes.
+// Genomes of different lengths are compared over the bits they have, and a
+// population without bits, such as expression trees, has a diversity of 0
+func Diversity(population []Genome) float64 {
+	var ones, counts []int
+	for _, g := range population {
+		bits := g.GetBits()
+		for i := 0; i < bits.GetSize(); i++ {
+			if i == len(counts) {
+				ones, counts = append(ones, 0), append(counts, 0)
+			}
+			ones[i] += bits.Get(i)
+			counts[i]++
+		}
+	}
+
+	if len(counts) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for i := range counts {
+		p := float64(ones[i]) / float64(counts[i])
+		total += 2 * math.Min(p, 1-p)
+	}
+	return total / float64(len(counts))
+}
+
+// converged returns true, with the reason, if 'population' has converged
+func (c *Convergence) converged(population []Genome, stagnant int) (bool, string) {
+	if c.Stagnation > 0 && stagnant >= c.Stagnation {
+		return true, fmt.Sprintf("no improvement for %v generations", stagnant)
+	}
+	if c.MinDiversity > 0 {
+		if diversity := Diversity(population); diversity < c.MinDiversity {
+			return true, fmt.Sprintf("diversity %.3f is below %v", diversity, c.MinDiversity)
+		}
+	}
+	return false, ""
+}
+
+func (c *Convergence) reseedFraction() float64 {
+	if c.ReseedFraction <= 0 {
+		return defaultReseedFraction
+	}
+	return c.ReseedFraction
+}
+
+func (c *Convergence) hypermutationRate() float64 {
+	if c.HypermutationRate <= 0 {
+		return defaultHypermutationRate
+	}
+	return c.HypermutationRate
+}
+
+func (c *Convergence) hypermutationGenerations() int {
+	if c.HypermutationGenerations <= 0 {
+		return defaultHypermutationGenerations
+	}
+	return c.HypermutationGenerations
+}
+
+// convergenceState - what the GeneticAlgorithm is doing in response to convergence
+// * lastResponse - the generation of the most recent response
+// * toCreate - the number of offspring of the next generation to create rather than breed
+// * hypermutating - the number of generations left to hypermutate
+type convergenceState struct {
+	lastResponse  int
+	toCreate      int
+	hypermutating int
+}
+
+// checkConvergence is called after each generation, it starts
+// a response if the population has converged
+func (ga *GeneticAlgorithm) checkConvergence() {
+	c, state := ga.Convergence, &ga.convergence
+	if c == nil {
+		return
+	}
+
+	if state.hypermutating > 0 {
+		state.hypermutating--
+		if state.hypermutating == 0 {
+			ga.notify(EventHypermutationEnded, "offspring are no longer hypermutated")
+		}
+		return
+	}
+
+	if state.toCreate > 0 {
+		// The response has not been bred yet, which can happen in steady state mode
+		return
+	}
+
+	generation := ga.runState.Generation
+	stagnant := ga.runState.StagnantGenerations
+	if sinceResponse := generation - state.lastResponse; state.lastResponse > 0 && sinceResponse < stagnant {
+		stagnant = sinceResponse
+	}
+
+	converged, reason := c.converged(ga.population, stagnant)
+	if !converged {
+		return
+	}
+	ga.notify(EventConverged, reason)
+	state.lastResponse = generation
+
+	switch c.Response {
+	case Reseed:
+		state.toCreate = int(math.Max(1, math.Round(c.reseedFraction()*float64(ga.populationSize))))
+		ga.notify(EventReseeded, fmt.Sprintf("creating %v of the next generation's offspring", state.toCreate))
+	case Hypermutate:
+		state.hypermutating = c.hypermutationGenerations()
+		ga.notify(EventHypermutationStarted, fmt.Sprintf("hypermutating offspring at rate %v for %v generations",
+			c.hypermutationRate(), state.hypermutating))
+	case Restart:
+		state.toCreate = ga.populationSize
+		ga.notify(EventRestarted, "creating every offspring of the next generation")
+	}
+}
+
+// takeToCreate returns the number of offspring that should be created rather than bred
+func (ga *GeneticAlgorithm) takeToCreate() int {
+	ret := ga.convergence.toCreate
+	ga.convergence.toCreate = 0
+	return ret
+}
+
+// hypermutationRate returns the rate offspring should be hypermutated at, 0 when they should not be
+func (ga *GeneticAlgorithm) hypermutationRate() float64 {
+	if ga.convergence.hypermutating == 0 {
+		return 0
+	}
+	return ga.Convergence.hypermutationRate()
+}
+
+// hypermutate returns a copy of 'g' with each bit flipped with probability 'rate'.
+// The copy keeps the lineage of 'g' but not its attributes, which describe its old bits
+func hypermutate(g Genome, rate float64) Genome {
+	if rate <= 0 {
+		return g
+	}
+
+	ret := copyContent(g)
+	copyLineage(g, ret)
+	flipBits(ret.GetBits(), rate)
+	return ret
+}
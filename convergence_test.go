@@ -0,0 +1,145 @@
+package goga_test
+
+import (
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type ConvergenceSuite struct {
+}
+
+var _ = Suite(&ConvergenceSuite{})
+
+type MyObserverRecorder struct {
+	Events []goga.Event
+}
+
+func (mo *MyObserverRecorder) OnEvent(event goga.Event) {
+	mo.Events = append(mo.Events, event)
+}
+
+func (mo *MyObserverRecorder) Types() []goga.EventType {
+	ret := make([]goga.EventType, len(mo.Events))
+	for i, e := range mo.Events {
+		ret[i] = e.Type
+	}
+	return ret
+}
+
+func (mo *MyObserverRecorder) Generations() []int {
+	ret := make([]int, len(mo.Events))
+	for i, e := range mo.Events {
+		ret[i] = e.Generation
+	}
+	return ret
+}
+
+func (s *ConvergenceSuite) TestShouldMeasureDiversity(t *C) {
+	t.Assert(goga.Diversity(nil), Equals, 0.0)
+	t.Assert(goga.Diversity([]goga.Genome{
+		helperGenome(t, "0101"),
+		helperGenome(t, "0101"),
+	}), Equals, 0.0)
+	t.Assert(goga.Diversity([]goga.Genome{
+		helperGenome(t, "0101"),
+		helperGenome(t, "1010"),
+	}), Equals, 1.0)
+
+	// The last bit is only compared between the genomes that have it
+	t.Assert(goga.Diversity([]goga.Genome{
+		helperGenome(t, "00"),
+		helperGenome(t, "01"),
+		helperGenome(t, "000"),
+		helperGenome(t, "011"),
+	}), Equals, 2.0/3)
+}
+
+func (s *ConvergenceSuite) TestShouldRestartWhenStagnant(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	bitsetCreate := &MyBitsetCreateCounter{}
+	genAlgo.BitsetCreate = bitsetCreate
+	genAlgo.Simulator = &MySimulatorConstantFitness{Fitness: 1}
+	genAlgo.Convergence = &goga.Convergence{Stagnation: 3, Response: goga.Restart}
+	observer := &MyObserverRecorder{}
+	genAlgo.Observers = []goga.Observer{observer}
+	genAlgo.Terminator = goga.MaxGenerations(10)
+	genAlgo.Init(10, 1)
+	genAlgo.Simulate()
+
+	// Stagnation is counted again from each restart
+	t.Assert(observer.Types(), DeepEquals, []goga.EventType{
+		goga.EventConverged, goga.EventRestarted,
+		goga.EventConverged, goga.EventRestarted,
+	})
+	t.Assert(observer.Generations(), DeepEquals, []int{4, 4, 7, 7})
+	t.Assert(bitsetCreate.NumCalls, Equals, 30)
+}
+
+func (s *ConvergenceSuite) TestShouldReseedAFractionOfOffspring(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genomeCreate := &MyGenomeCreateCounter{}
+	genAlgo.GenomeCreate = genomeCreate
+	genAlgo.Convergence = &goga.Convergence{Stagnation: 2, Response: goga.Reseed, ReseedFraction: 0.3}
+	genAlgo.Terminator = goga.MaxGenerations(4)
+	genAlgo.Init(10, 1)
+	genAlgo.Simulate()
+
+	t.Assert(genomeCreate.NumCalls, Equals, 13)
+}
+
+func (s *ConvergenceSuite) TestShouldReseedInSteadyStateMode(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genomeCreate := &MyGenomeCreateCounter{}
+	genAlgo.GenomeCreate = genomeCreate
+	genAlgo.SteadyState = true
+	genAlgo.Convergence = &goga.Convergence{Stagnation: 2, Response: goga.Reseed}
+	genAlgo.Terminator = goga.MaxGenerations(4)
+	genAlgo.Init(10, 1)
+	genAlgo.Simulate()
+
+	t.Assert(genomeCreate.NumCalls, Equals, 15)
+}
+
+func (s *ConvergenceSuite) TestShouldHypermutateWhenDiversityIsLow(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.BitsetCreate = goga.NewConstantBitsetCreate(16, 0)
+	genAlgo.Convergence = &goga.Convergence{
+		MinDiversity:             0.01,
+		Response:                 goga.Hypermutate,
+		HypermutationRate:        0.5,
+		HypermutationGenerations: 2,
+	}
+	observer := &MyObserverRecorder{}
+	diversity := 0.0
+	genAlgo.Observers = []goga.Observer{observer, goga.ObserverFunc(func(event goga.Event) {
+		t.Assert(event.Message, Not(Equals), "")
+		if event.Type == goga.EventHypermutationEnded {
+			diversity = goga.Diversity(genAlgo.GetPopulation())
+		}
+	})}
+	genAlgo.Terminator = goga.MaxGenerations(4)
+	genAlgo.Init(10, 1)
+	genAlgo.Simulate()
+
+	t.Assert(observer.Types(), DeepEquals, []goga.EventType{
+		goga.EventConverged, goga.EventHypermutationStarted, goga.EventHypermutationEnded,
+	})
+	t.Assert(observer.Generations(), DeepEquals, []int{1, 1, 3})
+
+	// Every genome was the same until it was hypermutated
+	t.Assert(diversity > 0.2, IsTrue)
+}
+
+func (s *ConvergenceSuite) TestShouldNotRespondWithoutConvergence(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(64)
+	genAlgo.Simulator = &MySimulatorIncreasingFitness{}
+	genAlgo.Convergence = &goga.Convergence{Stagnation: 2}
+	observer := &MyObserverRecorder{}
+	genAlgo.Observers = []goga.Observer{observer}
+	genAlgo.Terminator = goga.MaxGenerations(5)
+	genAlgo.Init(10, 1)
+	genAlgo.Simulate()
+
+	t.Assert(observer.Events, HasLen, 0)
+}
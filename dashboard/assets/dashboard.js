@@ -0,0 +1,123 @@
+"use strict";
+
+const pollInterval = 1000;
+let lastGeneration = -1;
+
+function drawChart(canvas, series, min, max) {
+  const context = canvas.getContext("2d");
+  const width = canvas.width, height = canvas.height, margin = 40;
+  context.clearRect(0, 0, width, height);
+
+  const values = series.flatMap((s) => s.values);
+  if (values.length === 0) {
+    return;
+  }
+  if (min === undefined) {
+    min = Math.min(...values);
+  }
+  if (max === undefined) {
+    max = Math.max(...values);
+  }
+  if (max === min) {
+    max = min + 1;
+  }
+
+  context.strokeStyle = "#ccc";
+  context.fillStyle = "#666";
+  context.font = "11px sans-serif";
+  context.beginPath();
+  context.moveTo(margin, 0);
+  context.lineTo(margin, height - 1);
+  context.lineTo(width, height - 1);
+  context.stroke();
+  context.fillText(String(Math.round(max * 1000) / 1000), 2, 10);
+  context.fillText(String(Math.round(min * 1000) / 1000), 2, height - 4);
+
+  for (const s of series) {
+    const n = s.values.length;
+    context.strokeStyle = s.colour;
+    context.beginPath();
+    s.values.forEach((value, i) => {
+      const x = margin + (n === 1 ? 0 : (i / (n - 1)) * (width - margin - 1));
+      const y = (height - 1) - ((value - min) / (max - min)) * (height - 2);
+      if (i === 0) {
+        context.moveTo(x, y);
+      } else {
+        context.lineTo(x, y);
+      }
+    });
+    context.stroke();
+  }
+}
+
+function showElite(status) {
+  const image = document.getElementById("elite-image");
+  const text = document.getElementById("elite-text");
+  if (status.eliteContentType.startsWith("image/")) {
+    image.src = "elite?generation=" + status.generation;
+    image.hidden = false;
+    return;
+  }
+  fetch("elite").then((response) => response.text()).then((elite) => {
+    text.textContent = elite;
+  });
+}
+
+function showStatus(status) {
+  document.getElementById("generation").textContent = status.generation;
+  document.getElementById("evaluations").textContent = status.evaluations;
+  document.getElementById("elapsed").textContent = status.elapsedSeconds.toFixed(1) + "s";
+  document.getElementById("best").textContent = status.bestFitness;
+  document.getElementById("stagnant").textContent = status.stagnantGenerations;
+
+  let state = status.generation > 0 ? "running" : "waiting for the first generation";
+  if (status.stopped) {
+    state = "stopped";
+  } else if (status.paused) {
+    state = "paused";
+  }
+  document.getElementById("state").textContent = state;
+
+  document.getElementById("controls").hidden = !status.controllable;
+  document.getElementById("pause").disabled = status.paused || status.stopped;
+  document.getElementById("resume").disabled = !status.paused || status.stopped;
+  document.getElementById("stop").disabled = status.stopped;
+
+  if (status.generation !== lastGeneration) {
+    lastGeneration = status.generation;
+    const history = status.history;
+    drawChart(document.getElementById("fitness"), [
+      { values: history.eliteFitness, colour: "#c0392b" },
+      { values: history.meanFitness, colour: "#2980b9" },
+    ]);
+    drawChart(document.getElementById("diversity"), [
+      { values: history.diversity, colour: "#27ae60" },
+    ], 0, 1);
+    if (status.generation > 0) {
+      showElite(status);
+    }
+  }
+}
+
+function poll() {
+  fetch("status")
+    .then((response) => response.json())
+    .then(showStatus)
+    .catch(() => {
+      document.getElementById("state").textContent = "disconnected";
+    })
+    .finally(() => setTimeout(poll, pollInterval));
+}
+
+for (const action of ["pause", "resume", "stop"]) {
+  document.getElementById(action).addEventListener("click", () => {
+    fetch(action, {
+      method: "POST",
+      headers: { "X-Requested-With": "dashboard" },
+    }).then(() => {
+      lastGeneration = -1;
+    });
+  });
+}
+
+poll();
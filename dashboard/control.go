@@ -0,0 +1,20 @@
+package dashboard
+
+// Controller - an interface to an object that controls a running GeneticAlgorithm,
+// its methods are called from the dashboard's HTTP handlers
+// * Pause - stops the run after its current generation until Resume is called
+// * Resume - continues a paused run
+// * Stop - ends the run after its current generation, even if it is paused
+// * Paused - returns true while the run is paused
+// * Stopped - returns true once Stop has been called
+//
+// It is implemented by *goga.GeneticAlgorithm, i.e.
+//
+//	board.Controller = &genAlgo
+type Controller interface {
+	Pause()
+	Resume()
+	Stop()
+	Paused() bool
+	Stopped() bool
+}
@@ -0,0 +1,232 @@
+package dashboard
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tomcraven/goga"
+)
+
+// DefaultHistorySize - the number of generations a Dashboard charts by default
+const DefaultHistorySize = 1000
+
+// ControlHeader - the header the dashboard's page sends with its pause, resume and
+// stop requests. Requests without it are refused, a page on another site can't set it
+// without the browser first asking permission, which the dashboard never gives, so
+// other sites can't control a run through the browser of someone viewing them
+const ControlHeader = "X-Requested-With"
+
+//go:embed assets
+var assets embed.FS
+
+// history - the statistics of the most recent generations, oldest first
+type history struct {
+	Generations  []int     `json:"generations"`
+	EliteFitness []int     `json:"eliteFitness"`
+	MeanFitness  []float64 `json:"meanFitness"`
+	Diversity    []float64 `json:"diversity"`
+}
+
+func (h *history) add(generation, eliteFitness int, meanFitness, diversity float64, size int) {
+	h.Generations = append(h.Generations, generation)
+	h.EliteFitness = append(h.EliteFitness, eliteFitness)
+	h.MeanFitness = append(h.MeanFitness, meanFitness)
+	h.Diversity = append(h.Diversity, diversity)
+	if excess := len(h.Generations) - size; excess > 0 {
+		h.Generations = h.Generations[excess:]
+		h.EliteFitness = h.EliteFitness[excess:]
+		h.MeanFitness = h.MeanFitness[excess:]
+		h.Diversity = h.Diversity[excess:]
+	}
+}
+
+func (h *history) copy() history {
+	return history{
+		Generations:  append([]int{}, h.Generations...),
+		EliteFitness: append([]int{}, h.EliteFitness...),
+		MeanFitness:  append([]float64{}, h.MeanFitness...),
+		Diversity:    append([]float64{}, h.Diversity...),
+	}
+}
+
+// status - what the dashboard's page polls for
+type status struct {
+	Generation          int     `json:"generation"`
+	Evaluations         int     `json:"evaluations"`
+	ElapsedSeconds      float64 `json:"elapsedSeconds"`
+	BestFitness         int     `json:"bestFitness"`
+	StagnantGenerations int     `json:"stagnantGenerations"`
+	History             history `json:"history"`
+	EliteContentType    string  `json:"eliteContentType"`
+	Controllable        bool    `json:"controllable"`
+	Paused              bool    `json:"paused"`
+	Stopped             bool    `json:"stopped"`
+}
+
+// Dashboard -
+// Implements goga.Monitor and http.Handler to serve a page that charts a running
+// GeneticAlgorithm's elite and mean fitness and the diversity of its population,
+// shows its current elite with a Renderer and, if it has a Controller, has buttons
+// to pause, resume and stop the run. Everything the page needs is served by the
+// handler so it works without access to the internet, i.e.
+//
+//	board := dashboard.New(dashboard.NewTextRenderer(decodeString))
+//	genAlgo.Monitors = append(genAlgo.Monitors, board)
+//	go http.ListenAndServe("127.0.0.1:8080", board)
+//
+// The handler can also be mounted under a prefix with http.StripPrefix
+type Dashboard struct {
+	// Controller, if set, is used by the pause, resume and stop buttons
+	Controller Controller
+
+	// HistorySize is the number of generations charted, DefaultHistorySize if not set
+	HistorySize int
+
+	renderer Renderer
+	mux      *http.ServeMux
+	mutex    sync.Mutex
+	state    goga.RunState
+	elite    goga.Genome
+	history  history
+}
+
+// New returns a dashboard that shows the elite with 'renderer', or BitsRenderer if it is nil
+func New(renderer Renderer) *Dashboard {
+	if renderer == nil {
+		renderer = BitsRenderer
+	}
+
+	d := &Dashboard{
+		renderer: renderer,
+		mux:      http.NewServeMux(),
+	}
+	static, _ := fs.Sub(assets, "assets")
+	d.mux.Handle("/", http.FileServer(http.FS(static)))
+	d.mux.HandleFunc("/status", d.serveStatus)
+	d.mux.HandleFunc("/elite", d.serveElite)
+	d.mux.HandleFunc("/pause", d.control(func(c Controller) { c.Pause() }))
+	d.mux.HandleFunc("/resume", d.control(func(c Controller) { c.Resume() }))
+	d.mux.HandleFunc("/stop", d.control(func(c Controller) { c.Stop() }))
+	return d
+}
+
+// OnSimulated - null implementation of goga.Monitor's 'OnSimulated'
+func (d *Dashboard) OnSimulated(goga.Genome, time.Duration) {
+}
+
+// OnGeneration records the statistics of the generation and a copy of its elite
+func (d *Dashboard) OnGeneration(state goga.RunState, population []goga.Genome) {
+	meanFitness := 0.0
+	for _, g := range population {
+		meanFitness += float64(g.GetFitness())
+	}
+	if len(population) > 0 {
+		meanFitness /= float64(len(population))
+	}
+	diversity := goga.Diversity(population)
+
+	var elite goga.Genome
+	if state.Elite != nil {
+		// The run carries on with the elite, so keep a copy the handlers can safely read
+		elite = goga.CopyGenome(state.Elite)
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if state.Generation <= 1 {
+		d.history = history{}
+	}
+	size := d.HistorySize
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+
+	state.Elite, state.EliteHistory = elite, nil
+	d.state = state
+	d.elite = elite
+	if elite != nil {
+		d.history.add(state.Generation, elite.GetFitness(), meanFitness, diversity, size)
+	}
+}
+
+// ServeHTTP serves the dashboard's page and the data it polls for
+func (d *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mux.ServeHTTP(w, r)
+}
+
+func (d *Dashboard) serveStatus(w http.ResponseWriter, r *http.Request) {
+	d.mutex.Lock()
+	s := status{
+		Generation:          d.state.Generation,
+		Evaluations:         d.state.Evaluations,
+		ElapsedSeconds:      d.state.Elapsed.Seconds(),
+		BestFitness:         d.state.BestFitness,
+		StagnantGenerations: d.state.StagnantGenerations,
+		History:             d.history.copy(),
+		EliteContentType:    d.renderer.ContentType(),
+	}
+	d.mutex.Unlock()
+
+	// The controller is asked without holding the lock as it may be blocked in OnGeneration
+	if d.Controller != nil {
+		s.Controllable = true
+		s.Paused = d.Controller.Paused()
+		s.Stopped = d.Controller.Stopped()
+	}
+
+	content, err := json.Marshal(s)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(content)
+}
+
+func (d *Dashboard) serveElite(w http.ResponseWriter, r *http.Request) {
+	d.mutex.Lock()
+	elite := d.elite
+	d.mutex.Unlock()
+
+	if elite == nil {
+		http.Error(w, "no generation has been simulated yet", http.StatusNotFound)
+		return
+	}
+
+	var buffer bytes.Buffer
+	if err := d.renderer.Render(&buffer, elite); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", d.renderer.ContentType())
+	w.Header().Set("Cache-Control", "no-store")
+	buffer.WriteTo(w)
+}
+
+// control returns a handler that applies 'f' to the Controller on a POST with ControlHeader
+func (d *Dashboard) control(f func(Controller)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.Header.Get(ControlHeader) == "" {
+			http.Error(w, "controls must be sent by the dashboard", http.StatusForbidden)
+			return
+		}
+		if d.Controller == nil {
+			http.Error(w, "the dashboard has no controller", http.StatusNotImplemented)
+			return
+		}
+		f(d.Controller)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
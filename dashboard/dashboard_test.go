@@ -0,0 +1,227 @@
+package dashboard_test
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/tomcraven/goga"
+	"github.com/tomcraven/goga/dashboard"
+	. "gopkg.in/check.v1"
+)
+
+type DashboardSuite struct {
+}
+
+var _ = Suite(&DashboardSuite{})
+
+type countOnesSimulator struct {
+	goga.NullSimulator
+}
+
+func (ms *countOnesSimulator) Simulate(g goga.Genome) {
+	bits := g.GetBits()
+	fitness := 0
+	for i := 0; i < bits.GetSize(); i++ {
+		fitness += bits.Get(i)
+	}
+	g.SetFitness(fitness)
+}
+
+func helperGeneticAlgorithm(monitors ...goga.Monitor) *goga.GeneticAlgorithm {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Monitors = monitors
+	genAlgo.Simulator = &countOnesSimulator{}
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(16)
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.UniformCrossover},
+		{P: 1, F: goga.Mutate},
+	})
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: goga.Roulette},
+	})
+	genAlgo.Init(10, 2)
+	return &genAlgo
+}
+
+func helperRequest(handler http.Handler, method, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+	return recorder
+}
+
+// helperControl sends a control request as the dashboard's page does
+func helperControl(handler http.Handler, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, path, nil)
+	request.Header.Set(dashboard.ControlHeader, "dashboard")
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+type helperStatus struct {
+	Generation   int  `json:"generation"`
+	Evaluations  int  `json:"evaluations"`
+	BestFitness  int  `json:"bestFitness"`
+	Controllable bool `json:"controllable"`
+	Paused       bool `json:"paused"`
+	Stopped      bool `json:"stopped"`
+	History      struct {
+		Generations  []int     `json:"generations"`
+		EliteFitness []int     `json:"eliteFitness"`
+		MeanFitness  []float64 `json:"meanFitness"`
+		Diversity    []float64 `json:"diversity"`
+	} `json:"history"`
+	EliteContentType string `json:"eliteContentType"`
+}
+
+func helperGetStatus(t *C, handler http.Handler) helperStatus {
+	response := helperRequest(handler, http.MethodGet, "/status")
+	t.Assert(response.Code, Equals, http.StatusOK)
+	t.Assert(response.Header().Get("Content-Type"), Equals, "application/json")
+
+	var ret helperStatus
+	t.Assert(json.Unmarshal(response.Body.Bytes(), &ret), IsNil)
+	return ret
+}
+
+func (s *DashboardSuite) TestShouldServeLocalAssets(t *C) {
+	board := dashboard.New(nil)
+	for path, contentType := range map[string]string{
+		"/":              "text/html",
+		"/dashboard.js":  "text/javascript",
+		"/dashboard.css": "text/css",
+	} {
+		response := helperRequest(board, http.MethodGet, path)
+		t.Assert(response.Code, Equals, http.StatusOK)
+		t.Assert(strings.HasPrefix(response.Header().Get("Content-Type"), contentType), Equals, true)
+		// Nothing is loaded from elsewhere
+		t.Assert(strings.Contains(response.Body.String(), "http://"), Equals, false)
+		t.Assert(strings.Contains(response.Body.String(), "https://"), Equals, false)
+	}
+
+	t.Assert(helperRequest(board, http.MethodGet, "/elite").Code, Equals, http.StatusNotFound)
+	t.Assert(helperGetStatus(t, board).Generation, Equals, 0)
+}
+
+func (s *DashboardSuite) TestShouldShowARun(t *C) {
+	board := dashboard.New(nil)
+	board.HistorySize = 3
+	genAlgo := helperGeneticAlgorithm(board)
+	genAlgo.Terminator = goga.MaxGenerations(5)
+	genAlgo.Simulate()
+
+	state := genAlgo.GetRunState()
+	status := helperGetStatus(t, board)
+	t.Assert(status.Generation, Equals, 5)
+	t.Assert(status.Evaluations, Equals, 50)
+	t.Assert(status.BestFitness, Equals, state.BestFitness)
+	t.Assert(status.Controllable, Equals, false)
+	t.Assert(status.EliteContentType, Equals, "text/plain; charset=utf-8")
+	t.Assert(status.History.Generations, DeepEquals, []int{3, 4, 5})
+	t.Assert(status.History.EliteFitness, DeepEquals, state.EliteHistory[2:])
+	t.Assert(status.History.MeanFitness, HasLen, 3)
+	t.Assert(status.History.Diversity[2], Equals, goga.Diversity(genAlgo.GetPopulation()))
+
+	response := helperRequest(board, http.MethodGet, "/elite")
+	t.Assert(response.Code, Equals, http.StatusOK)
+	t.Assert(response.Body.String(), Equals, state.Elite.GetBits().Hex())
+
+	// A new run starts a new history
+	genAlgo.Terminator = goga.MaxGenerations(1)
+	genAlgo.Simulate()
+	t.Assert(helperGetStatus(t, board).History.Generations, DeepEquals, []int{1})
+}
+
+func (s *DashboardSuite) TestShouldRenderEliteAsPNG(t *C) {
+	board := dashboard.New(dashboard.NewPNGRenderer(func(g goga.Genome) image.Image {
+		bits := g.GetBits()
+		img := image.NewGray(image.Rect(0, 0, bits.GetSize(), 1))
+		for i := 0; i < bits.GetSize(); i++ {
+			img.SetGray(i, 0, color.Gray{uint8(bits.Get(i) * 255)})
+		}
+		return img
+	}))
+	genAlgo := helperGeneticAlgorithm(board)
+	genAlgo.Terminator = goga.MaxGenerations(2)
+	genAlgo.Simulate()
+
+	response := helperRequest(board, http.MethodGet, "/elite")
+	t.Assert(response.Code, Equals, http.StatusOK)
+	t.Assert(response.Header().Get("Content-Type"), Equals, "image/png")
+	img, err := png.Decode(response.Body)
+	t.Assert(err, IsNil)
+
+	bits := genAlgo.GetRunState().Elite.GetBits()
+	for i := 0; i < bits.GetSize(); i++ {
+		t.Assert(img.(*image.Gray).GrayAt(i, 0).Y, Equals, uint8(bits.Get(i)*255))
+	}
+}
+
+func (s *DashboardSuite) TestShouldRejectControlsWithoutController(t *C) {
+	board := dashboard.New(nil)
+	t.Assert(helperControl(board, "/pause").Code, Equals, http.StatusNotImplemented)
+
+	board.Controller = helperGeneticAlgorithm()
+	response := helperRequest(board, http.MethodGet, "/pause")
+	t.Assert(response.Code, Equals, http.StatusMethodNotAllowed)
+	t.Assert(response.Header().Get("Allow"), Equals, http.MethodPost)
+}
+
+func (s *DashboardSuite) TestShouldPauseResumeAndStopARun(t *C) {
+	board := dashboard.New(nil)
+	genAlgo := helperGeneticAlgorithm(board)
+	board.Controller = genAlgo
+
+	t.Assert(helperControl(board, "/pause").Code, Equals, http.StatusNoContent)
+	done := make(chan bool)
+	go func() {
+		genAlgo.Simulate()
+		close(done)
+	}()
+
+	// The run pauses after its first generation
+	for helperGetStatus(t, board).Generation < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	status := helperGetStatus(t, board)
+	t.Assert(status.Controllable, Equals, true)
+	t.Assert(status.Paused, Equals, true)
+	t.Assert(status.Generation, Equals, 1)
+
+	t.Assert(helperControl(board, "/resume").Code, Equals, http.StatusNoContent)
+	for helperGetStatus(t, board).Generation < 10 {
+		time.Sleep(time.Millisecond)
+	}
+	t.Assert(genAlgo.Paused(), Equals, false)
+
+	t.Assert(helperControl(board, "/stop").Code, Equals, http.StatusNoContent)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the run did not stop")
+	}
+	t.Assert(genAlgo.GetTerminationReason(), Equals, "stopped")
+}
+
+func (s *DashboardSuite) TestShouldRejectCrossSiteControls(t *C) {
+	board := dashboard.New(nil)
+	genAlgo := helperGeneticAlgorithm(board)
+	board.Controller = genAlgo
+
+	// A form on another site can POST, but can't add the dashboard's header
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/pause", nil)
+	request.Header.Set("Origin", "http://example.com")
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	board.ServeHTTP(recorder, request)
+
+	t.Assert(recorder.Code, Equals, http.StatusForbidden)
+	t.Assert(genAlgo.Paused(), Equals, false)
+}
@@ -0,0 +1,62 @@
+package dashboard
+
+import (
+	"image"
+	"image/png"
+	"io"
+
+	"github.com/tomcraven/goga"
+)
+
+// Renderer - draws the phenotype of a genome, such as the image or string its
+// bits describe, for the dashboard to show the elite with
+// * ContentType - the MIME type of what Render writes, images are shown as
+// images and anything else as text
+// * Render - writes the phenotype of the genome to the writer
+type Renderer interface {
+	ContentType() string
+	Render(io.Writer, goga.Genome) error
+}
+
+type textRenderer struct {
+	f func(goga.Genome) string
+}
+
+// NewTextRenderer returns a Renderer that shows the string returned by 'f'
+func NewTextRenderer(f func(goga.Genome) string) Renderer {
+	return &textRenderer{f: f}
+}
+
+func (tr *textRenderer) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (tr *textRenderer) Render(w io.Writer, g goga.Genome) error {
+	_, err := io.WriteString(w, tr.f(g))
+	return err
+}
+
+type pngRenderer struct {
+	f func(goga.Genome) image.Image
+}
+
+// NewPNGRenderer returns a Renderer that shows the image returned by 'f' as a PNG,
+// e.g. a goga.PhenotypeCache of images can be reused with
+// NewPNGRenderer(func(g goga.Genome) image.Image { return images.Get(g).(image.Image) })
+func NewPNGRenderer(f func(goga.Genome) image.Image) Renderer {
+	return &pngRenderer{f: f}
+}
+
+func (pr *pngRenderer) ContentType() string {
+	return "image/png"
+}
+
+func (pr *pngRenderer) Render(w io.Writer, g goga.Genome) error {
+	return png.Encode(w, pr.f(g))
+}
+
+// BitsRenderer shows a genome's bitset in the format produced by goga.Bitset.Hex,
+// it is used when a Dashboard has no Renderer
+var BitsRenderer = NewTextRenderer(func(g goga.Genome) string {
+	return g.GetBits().Hex()
+})
@@ -0,0 +1,401 @@
+package distributed
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+
+	"github.com/tomcraven/goga"
+)
+
+const (
+	// DefaultHeartbeatTimeout - how long a worker can go without a heartbeat
+	// before its genomes are handed to other workers
+	DefaultHeartbeatTimeout = 5 * time.Second
+
+	// DefaultPollTimeout - how long a worker's request for a job blocks
+	// when there is nothing to simulate
+	DefaultPollTimeout = 1 * time.Second
+)
+
+// ErrCoordinatorClosed - returned to workers once the coordinator has been closed
+var ErrCoordinatorClosed = errors.New("coordinator closed")
+
+type job struct {
+	id         uint64
+	generation int
+	bits       []int
+	workerID   int
+	done       chan int
+}
+
+type workerState struct {
+	name          string
+	lastHeartbeat time.Time
+	jobs          map[uint64]*job
+}
+
+// Coordinator -
+// Implements goga.Simulator by handing each genome to a remote worker and
+// waiting for its fitness. Set it as the GeneticAlgorithm's Simulator and use
+// a parallelSimulations value at least as large as the number of workers
+// so every worker has a genome to simulate.
+// Workers that stop sending heartbeats are dropped and any genomes they were
+// simulating are queued for the remaining workers. Each genome is matched to
+// its own result so results arriving out of order are handled.
+type Coordinator struct {
+	// Exit is used to implement the Simulator's ExitFunc, if nil
+	// the coordinator never asks the algorithm to exit
+	Exit func(goga.Genome) bool
+
+	// HeartbeatTimeout and PollTimeout must be set before Listen is called,
+	// DefaultHeartbeatTimeout and DefaultPollTimeout are used if they are not positive
+	HeartbeatTimeout time.Duration
+	PollTimeout      time.Duration
+
+	mutex        sync.Mutex
+	workers      map[int]*workerState
+	queue        []*job
+	pending      map[uint64]*job
+	nextWorkerID int
+	nextJobID    uint64
+	generation   int
+	notify       chan struct{}
+	closed       chan struct{}
+	listener     net.Listener
+	server       *rpc.Server
+	conns        map[net.Conn]struct{}
+}
+
+// NewCoordinator returns a coordinator using the default heartbeat and poll timeouts
+func NewCoordinator() *Coordinator {
+	return &Coordinator{
+		HeartbeatTimeout: DefaultHeartbeatTimeout,
+		PollTimeout:      DefaultPollTimeout,
+		workers:          make(map[int]*workerState),
+		pending:          make(map[uint64]*job),
+		conns:            make(map[net.Conn]struct{}),
+		notify:           make(chan struct{}, 1),
+		closed:           make(chan struct{}),
+	}
+}
+
+// Listen starts accepting worker connections on 'address' in the background
+func (c *Coordinator) Listen(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	c.server = rpc.NewServer()
+	if err := c.server.RegisterName("Coordinator", &coordinatorService{c}); err != nil {
+		listener.Close()
+		return err
+	}
+
+	c.listener = listener
+	go c.accept()
+	go c.reapWorkers()
+	return nil
+}
+
+func (c *Coordinator) accept() {
+	for {
+		conn, err := c.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		c.mutex.Lock()
+		select {
+		case <-c.closed:
+			c.mutex.Unlock()
+			conn.Close()
+			return
+		default:
+		}
+		c.conns[conn] = struct{}{}
+		c.mutex.Unlock()
+
+		go c.serve(conn)
+	}
+}
+
+// serve handles a worker's requests until its connection is closed
+func (c *Coordinator) serve(conn net.Conn) {
+	c.server.ServeConn(conn)
+
+	c.mutex.Lock()
+	delete(c.conns, conn)
+	c.mutex.Unlock()
+}
+
+func (c *Coordinator) heartbeatTimeout() time.Duration {
+	if c.HeartbeatTimeout <= 0 {
+		return DefaultHeartbeatTimeout
+	}
+	return c.HeartbeatTimeout
+}
+
+func (c *Coordinator) pollTimeout() time.Duration {
+	if c.PollTimeout <= 0 {
+		return DefaultPollTimeout
+	}
+	return c.PollTimeout
+}
+
+// Addr returns the address the coordinator is listening on
+func (c *Coordinator) Addr() net.Addr {
+	if c.listener == nil {
+		return nil
+	}
+	return c.listener.Addr()
+}
+
+// Close stops listening for workers and closes the connections of those already
+// connected, any genomes still waiting on a result are released with their fitness unchanged
+func (c *Coordinator) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	select {
+	case <-c.closed:
+		return nil
+	default:
+	}
+	close(c.closed)
+
+	var err error
+	if c.listener != nil {
+		err = c.listener.Close()
+	}
+	for conn := range c.conns {
+		conn.Close()
+	}
+	return err
+}
+
+// NumWorkers returns the number of currently registered workers
+func (c *Coordinator) NumWorkers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.workers)
+}
+
+// OnBeginSimulation - starts a new generation, workers are told about it with their next job
+func (c *Coordinator) OnBeginSimulation() {
+	c.mutex.Lock()
+	c.generation++
+	c.mutex.Unlock()
+}
+
+// Simulate queues the genome for a worker and blocks until its fitness is known
+func (c *Coordinator) Simulate(g goga.Genome) {
+	c.mutex.Lock()
+	c.nextJobID++
+	j := &job{
+		id:         c.nextJobID,
+		generation: c.generation,
+		bits:       bitsetToBits(g.GetBits()),
+		done:       make(chan int, 1),
+	}
+	c.pending[j.id] = j
+	c.pushLocked(j, false)
+	c.mutex.Unlock()
+
+	select {
+	case fitness := <-j.done:
+		g.SetFitness(fitness)
+	case <-c.closed:
+	}
+}
+
+// OnEndSimulation - null implementation, all genomes have a result by the time this is called
+func (c *Coordinator) OnEndSimulation() {
+}
+
+// ExitFunc - defers to the coordinator's Exit field
+func (c *Coordinator) ExitFunc(g goga.Genome) bool {
+	if c.Exit == nil {
+		return false
+	}
+	return c.Exit(g)
+}
+
+func (c *Coordinator) pushLocked(j *job, front bool) {
+	j.workerID = 0
+	if front {
+		c.queue = append([]*job{j}, c.queue...)
+	} else {
+		c.queue = append(c.queue, j)
+	}
+	c.signal()
+}
+
+func (c *Coordinator) signal() {
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Coordinator) register(name string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.nextWorkerID++
+	c.workers[c.nextWorkerID] = &workerState{
+		name:          name,
+		lastHeartbeat: time.Now(),
+		jobs:          make(map[uint64]*job),
+	}
+	return c.nextWorkerID
+}
+
+func (c *Coordinator) heartbeat(workerID int) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	w, ok := c.workers[workerID]
+	if ok {
+		w.lastHeartbeat = time.Now()
+	}
+	return ok
+}
+
+func (c *Coordinator) takeJob(workerID int) (*job, bool, error) {
+	timeout := time.NewTimer(c.pollTimeout())
+	defer timeout.Stop()
+
+	for {
+		c.mutex.Lock()
+		w, ok := c.workers[workerID]
+		if !ok {
+			c.mutex.Unlock()
+			return nil, false, errors.New(errUnknownWorker)
+		}
+		if len(c.queue) > 0 {
+			j := c.queue[0]
+			c.queue = c.queue[1:]
+			j.workerID = workerID
+			w.jobs[j.id] = j
+			if len(c.queue) > 0 {
+				c.signal()
+			}
+			c.mutex.Unlock()
+			return j, true, nil
+		}
+		c.mutex.Unlock()
+
+		select {
+		case <-c.notify:
+		case <-timeout.C:
+			return nil, false, nil
+		case <-c.closed:
+			return nil, false, ErrCoordinatorClosed
+		}
+	}
+}
+
+func (c *Coordinator) complete(workerID int, jobID uint64, fitness int) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// A worker that was given up on has had its jobs re-queued
+	w, ok := c.workers[workerID]
+	if !ok {
+		return false
+	}
+	delete(w.jobs, jobID)
+
+	j, ok := c.pending[jobID]
+	if !ok {
+		return false
+	}
+	delete(c.pending, jobID)
+
+	// The job may have been re-queued after the worker was presumed dead
+	for i, queued := range c.queue {
+		if queued == j {
+			c.queue = append(c.queue[:i], c.queue[i+1:]...)
+			break
+		}
+	}
+	if j.workerID != 0 && j.workerID != workerID {
+		if other, ok := c.workers[j.workerID]; ok {
+			delete(other.jobs, jobID)
+		}
+	}
+
+	j.done <- fitness
+	return true
+}
+
+func (c *Coordinator) reapWorkers() {
+	ticker := time.NewTicker(c.heartbeatTimeout() / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.reapWorkersOnce(time.Now())
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+func (c *Coordinator) reapWorkersOnce(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for id, w := range c.workers {
+		if now.Sub(w.lastHeartbeat) <= c.heartbeatTimeout() {
+			continue
+		}
+
+		delete(c.workers, id)
+		for _, j := range w.jobs {
+			if _, ok := c.pending[j.id]; ok {
+				c.pushLocked(j, true)
+			}
+		}
+	}
+}
+
+// coordinatorService holds the methods exposed to workers over rpc, keeping
+// them off the Coordinator's public api
+type coordinatorService struct {
+	c *Coordinator
+}
+
+func (s *coordinatorService) Register(args RegisterArgs, reply *RegisterReply) error {
+	reply.WorkerID = s.c.register(args.Name)
+	reply.HeartbeatInterval = s.c.heartbeatTimeout() / 3
+	return nil
+}
+
+func (s *coordinatorService) Heartbeat(args HeartbeatArgs, reply *HeartbeatReply) error {
+	reply.Known = s.c.heartbeat(args.WorkerID)
+	return nil
+}
+
+func (s *coordinatorService) NextJob(args JobArgs, reply *Job) error {
+	j, ok, err := s.c.takeJob(args.WorkerID)
+	if err != nil || !ok {
+		return err
+	}
+
+	reply.Available = true
+	reply.ID = j.id
+	reply.Generation = j.generation
+	reply.Bits = j.bits
+	return nil
+}
+
+func (s *coordinatorService) SubmitResult(args Result, reply *ResultReply) error {
+	reply.Accepted = s.c.complete(args.WorkerID, args.JobID, args.Fitness)
+	return nil
+}
@@ -0,0 +1,298 @@
+package distributed_test
+
+import (
+	"net/rpc"
+	"sync"
+	"time"
+
+	"github.com/tomcraven/goga"
+	"github.com/tomcraven/goga/distributed"
+	. "gopkg.in/check.v1"
+)
+
+type CoordinatorSuite struct {
+	coordinator *distributed.Coordinator
+}
+
+func (s *CoordinatorSuite) SetUpTest(t *C) {
+	s.coordinator = distributed.NewCoordinator()
+	s.coordinator.HeartbeatTimeout = 200 * time.Millisecond
+	s.coordinator.PollTimeout = 50 * time.Millisecond
+	t.Assert(s.coordinator.Listen("127.0.0.1:0"), IsNil)
+}
+func (s *CoordinatorSuite) TearDownTest(t *C) {
+	s.coordinator.Close()
+	s.coordinator = nil
+}
+
+var _ = Suite(&CoordinatorSuite{})
+
+type countOnesSimulator struct {
+	m              sync.Mutex
+	numSimulations int
+	numBeginCalls  int
+	numEndCalls    int
+}
+
+func (ms *countOnesSimulator) OnBeginSimulation() {
+	ms.numBeginCalls++
+}
+func (ms *countOnesSimulator) Simulate(g goga.Genome) {
+	ms.m.Lock()
+	ms.numSimulations++
+	ms.m.Unlock()
+
+	bits := g.GetBits()
+	fitness := 0
+	for i := 0; i < bits.GetSize(); i++ {
+		fitness += bits.Get(i)
+	}
+	g.SetFitness(fitness)
+}
+func (ms *countOnesSimulator) OnEndSimulation() {
+	ms.numEndCalls++
+}
+func (ms *countOnesSimulator) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+type randomBitsetCreate struct {
+	size int
+}
+
+func (bc *randomBitsetCreate) Go() goga.Bitset {
+	b := goga.Bitset{}
+	b.Create(bc.size)
+	for i := 0; i < bc.size; i++ {
+		b.Set(i, (i*7+bc.size)%3%2)
+	}
+	bc.size++
+	return b
+}
+
+func (s *CoordinatorSuite) startWorkers(numWorkers int) ([]*distributed.Worker, []*countOnesSimulator, *sync.WaitGroup) {
+	workers := make([]*distributed.Worker, numWorkers)
+	simulators := make([]*countOnesSimulator, numWorkers)
+	waitGroup := new(sync.WaitGroup)
+	for i := range workers {
+		simulators[i] = &countOnesSimulator{}
+		workers[i] = distributed.NewWorker(simulators[i])
+
+		waitGroup.Add(1)
+		go func(w *distributed.Worker) {
+			defer waitGroup.Done()
+			w.Run(s.coordinator.Addr().String())
+		}(workers[i])
+	}
+
+	for s.coordinator.NumWorkers() < numWorkers {
+		time.Sleep(time.Millisecond)
+	}
+	return workers, simulators, waitGroup
+}
+
+func (s *CoordinatorSuite) TestShouldSimulateGenomesOnWorkers(t *C) {
+	numWorkers := 3
+	workers, simulators, waitGroup := s.startWorkers(numWorkers)
+
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = s.coordinator
+	genAlgo.BitsetCreate = &randomBitsetCreate{size: 10}
+
+	populationSize := 20
+	genAlgo.Init(populationSize, numWorkers*2)
+
+	// The first generation is simulated and passed to the exit function
+	checked := false
+	genAlgo.SimulateUntil(func(goga.Genome) bool {
+		for _, g := range genAlgo.GetPopulation() {
+			bits := g.GetBits()
+			expectedFitness := 0
+			for i := 0; i < bits.GetSize(); i++ {
+				expectedFitness += bits.Get(i)
+			}
+			t.Assert(g.GetFitness(), Equals, expectedFitness)
+		}
+		checked = true
+		return true
+	})
+	t.Assert(checked, Equals, true)
+
+	for _, w := range workers {
+		w.Stop()
+	}
+	s.coordinator.Close()
+	waitGroup.Wait()
+
+	totalSimulations := 0
+	for _, simulator := range simulators {
+		totalSimulations += simulator.numSimulations
+		t.Assert(simulator.numBeginCalls, Equals, simulator.numEndCalls)
+	}
+	t.Assert(totalSimulations, Equals, populationSize)
+}
+
+func (s *CoordinatorSuite) TestShouldRequeueGenomesFromDeadWorkers(t *C) {
+
+	// A worker that takes a job and then disappears without a heartbeat
+	client, err := rpc.Dial("tcp", s.coordinator.Addr().String())
+	t.Assert(err, IsNil)
+	defer client.Close()
+
+	var registerReply distributed.RegisterReply
+	t.Assert(client.Call("Coordinator.Register", distributed.RegisterArgs{}, &registerReply), IsNil)
+
+	b := goga.Bitset{}
+	b.Create(4)
+	b.SetAll(1)
+	g := goga.NewGenome(b)
+
+	simulated := make(chan struct{})
+	go func() {
+		s.coordinator.Simulate(g)
+		close(simulated)
+	}()
+
+	var j distributed.Job
+	for !j.Available {
+		err = client.Call("Coordinator.NextJob", distributed.JobArgs{WorkerID: registerReply.WorkerID}, &j)
+		t.Assert(err, IsNil)
+	}
+
+	workers, _, waitGroup := s.startWorkers(1)
+	select {
+	case <-simulated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("genome was not re-queued")
+	}
+	t.Assert(g.GetFitness(), Equals, 4)
+
+	// The dead worker has been dropped and its late result is ignored
+	var resultReply distributed.ResultReply
+	err = client.Call("Coordinator.SubmitResult",
+		distributed.Result{WorkerID: registerReply.WorkerID, JobID: j.ID, Fitness: 100}, &resultReply)
+	t.Assert(err, IsNil)
+	t.Assert(resultReply.Accepted, Equals, false)
+	t.Assert(g.GetFitness(), Equals, 4)
+
+	workers[0].Stop()
+	waitGroup.Wait()
+}
+
+func (s *CoordinatorSuite) TestShouldRejectResultsFromUnregisteredWorkers(t *C) {
+	client, err := rpc.Dial("tcp", s.coordinator.Addr().String())
+	t.Assert(err, IsNil)
+	defer client.Close()
+
+	var registerReply distributed.RegisterReply
+	t.Assert(client.Call("Coordinator.Register", distributed.RegisterArgs{}, &registerReply), IsNil)
+
+	b := goga.Bitset{}
+	b.Create(4)
+	b.SetAll(1)
+	g := goga.NewGenome(b)
+
+	simulated := make(chan struct{})
+	go func() {
+		s.coordinator.Simulate(g)
+		close(simulated)
+	}()
+
+	var j distributed.Job
+	for !j.Available {
+		err = client.Call("Coordinator.NextJob", distributed.JobArgs{WorkerID: registerReply.WorkerID}, &j)
+		t.Assert(err, IsNil)
+	}
+
+	// Miss heartbeats until the coordinator gives up, the job is still waiting on a result
+	for s.coordinator.NumWorkers() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	var resultReply distributed.ResultReply
+	err = client.Call("Coordinator.SubmitResult",
+		distributed.Result{WorkerID: registerReply.WorkerID, JobID: j.ID, Fitness: 100}, &resultReply)
+	t.Assert(err, IsNil)
+	t.Assert(resultReply.Accepted, Equals, false)
+
+	workers, _, waitGroup := s.startWorkers(1)
+	<-simulated
+	t.Assert(g.GetFitness(), Equals, 4)
+
+	workers[0].Stop()
+	waitGroup.Wait()
+}
+
+func (s *CoordinatorSuite) TestShouldMatchResultsToGenomesOutOfOrder(t *C) {
+	client, err := rpc.Dial("tcp", s.coordinator.Addr().String())
+	t.Assert(err, IsNil)
+	defer client.Close()
+
+	var registerReply distributed.RegisterReply
+	t.Assert(client.Call("Coordinator.Register", distributed.RegisterArgs{}, &registerReply), IsNil)
+
+	numGenomes := 5
+	genomes := make([]goga.Genome, numGenomes)
+	waitGroup := new(sync.WaitGroup)
+	for i := range genomes {
+		b := goga.Bitset{}
+		b.Create(i + 1)
+		genomes[i] = goga.NewGenome(b)
+
+		waitGroup.Add(1)
+		go func(g goga.Genome) {
+			defer waitGroup.Done()
+			s.coordinator.Simulate(g)
+		}(genomes[i])
+	}
+
+	jobs := []distributed.Job{}
+	for len(jobs) < numGenomes {
+		var j distributed.Job
+		t.Assert(client.Call("Coordinator.NextJob", distributed.JobArgs{WorkerID: registerReply.WorkerID}, &j), IsNil)
+		if j.Available {
+			jobs = append(jobs, j)
+		}
+	}
+
+	// Reply in reverse order with the genome's size as its fitness
+	for i := len(jobs) - 1; i >= 0; i-- {
+		var resultReply distributed.ResultReply
+		result := distributed.Result{WorkerID: registerReply.WorkerID, JobID: jobs[i].ID, Fitness: len(jobs[i].Bits)}
+		t.Assert(client.Call("Coordinator.SubmitResult", result, &resultReply), IsNil)
+		t.Assert(resultReply.Accepted, Equals, true)
+	}
+
+	waitGroup.Wait()
+	for i, g := range genomes {
+		t.Assert(g.GetFitness(), Equals, i+1)
+	}
+}
+
+func (s *CoordinatorSuite) TestShouldUseDefaultTimeoutsWhenNotSet(t *C) {
+	coordinator := distributed.NewCoordinator()
+	coordinator.HeartbeatTimeout = 0
+	coordinator.PollTimeout = 0
+	t.Assert(coordinator.Listen("127.0.0.1:0"), IsNil)
+	defer coordinator.Close()
+
+	client, err := rpc.Dial("tcp", coordinator.Addr().String())
+	t.Assert(err, IsNil)
+	defer client.Close()
+
+	var registerReply distributed.RegisterReply
+	t.Assert(client.Call("Coordinator.Register", distributed.RegisterArgs{}, &registerReply), IsNil)
+	t.Assert(registerReply.HeartbeatInterval, Equals, distributed.DefaultHeartbeatTimeout/3)
+}
+
+func (s *CoordinatorSuite) TestShouldCloseWorkerConnections(t *C) {
+	client, err := rpc.Dial("tcp", s.coordinator.Addr().String())
+	t.Assert(err, IsNil)
+	defer client.Close()
+
+	var registerReply distributed.RegisterReply
+	t.Assert(client.Call("Coordinator.Register", distributed.RegisterArgs{}, &registerReply), IsNil)
+
+	s.coordinator.Close()
+	err = client.Call("Coordinator.Register", distributed.RegisterArgs{}, &registerReply)
+	t.Assert(err, NotNil)
+}
@@ -0,0 +1,75 @@
+package distributed
+
+import (
+	"time"
+
+	"github.com/tomcraven/goga"
+)
+
+// The types in this file are passed between coordinator and workers
+// over net/rpc and must remain gob encodable
+
+// RegisterArgs - sent by a worker when it first connects to a coordinator
+type RegisterArgs struct {
+	Name string
+}
+
+// RegisterReply - tells a newly registered worker its id and how often
+// it must send heartbeats to stay registered
+type RegisterReply struct {
+	WorkerID          int
+	HeartbeatInterval time.Duration
+}
+
+// HeartbeatArgs - sent periodically by a worker to signal it is alive
+type HeartbeatArgs struct {
+	WorkerID int
+}
+
+// HeartbeatReply - Known is false when the coordinator has already
+// given up on the worker, the worker should then register again
+type HeartbeatReply struct {
+	Known bool
+}
+
+// JobArgs - sent by a worker asking for a genome to simulate
+type JobArgs struct {
+	WorkerID int
+}
+
+// Job - a genome to simulate, Available is false when the coordinator
+// had no work queued before the poll timed out
+type Job struct {
+	Available  bool
+	ID         uint64
+	Generation int
+	Bits       []int
+}
+
+// Result - the fitness a worker calculated for a job
+type Result struct {
+	WorkerID int
+	JobID    uint64
+	Fitness  int
+}
+
+// ResultReply - Accepted is false if the job had already been completed
+// by another worker, or the worker is no longer registered
+type ResultReply struct {
+	Accepted bool
+}
+
+func bitsetToBits(b *goga.Bitset) []int {
+	bits := make([]int, b.GetSize())
+	copy(bits, b.GetAll())
+	return bits
+}
+
+func bitsToBitset(bits []int) goga.Bitset {
+	b := goga.Bitset{}
+	b.Create(len(bits))
+	for i, bit := range bits {
+		b.Set(i, bit)
+	}
+	return b
+}
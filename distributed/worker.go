@@ -0,0 +1,180 @@
+package distributed
+
+import (
+	"net/rpc"
+	"sync"
+	"time"
+
+	"github.com/tomcraven/goga"
+)
+
+const errUnknownWorker = "unknown worker"
+
+// Worker -
+// Hosts a goga.Simulator and simulates genomes handed out by a Coordinator.
+// The simulator's OnBeginSimulation and OnEndSimulation are called as the
+// worker sees jobs from a new generation, so they bracket only the genomes
+// this worker simulated.
+type Worker struct {
+	Simulator goga.Simulator
+	Name      string
+
+	mutex      sync.Mutex
+	stop       chan struct{}
+	generation int
+	simulating bool
+}
+
+// NewWorker returns a worker that simulates genomes using 'simulator'
+func NewWorker(simulator goga.Simulator) *Worker {
+	return &Worker{
+		Simulator: simulator,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Stop asks a running worker to return from Run once its current genome is simulated
+func (w *Worker) Stop() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	select {
+	case <-w.stop:
+	default:
+		close(w.stop)
+	}
+}
+
+func (w *Worker) stopped() bool {
+	select {
+	case <-w.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run connects to the coordinator at 'address' and simulates genomes until
+// Stop is called or the connection to the coordinator is lost
+func (w *Worker) Run(address string) error {
+	client, err := rpc.Dial("tcp", address)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	workerID, interval, err := w.register(client)
+	if err != nil {
+		return err
+	}
+
+	// reregister registers again if the coordinator has given up on 'staleID', unless
+	// the other goroutine has already done so
+	var idMutex sync.Mutex
+	reregister := func(staleID int) error {
+		idMutex.Lock()
+		defer idMutex.Unlock()
+
+		if workerID != staleID {
+			return nil
+		}
+		id, _, err := w.register(client)
+		if err == nil {
+			workerID = id
+		}
+		return err
+	}
+
+	heartbeatDone := make(chan struct{})
+	defer close(heartbeatDone)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				idMutex.Lock()
+				args := HeartbeatArgs{WorkerID: workerID}
+				idMutex.Unlock()
+
+				// The coordinator gave up on us, most likely because a heartbeat
+				// was late, so register again rather than wait for the next job
+				var reply HeartbeatReply
+				if client.Call("Coordinator.Heartbeat", args, &reply) == nil && !reply.Known {
+					reregister(args.WorkerID)
+				}
+			case <-heartbeatDone:
+				return
+			}
+		}
+	}()
+
+	for !w.stopped() {
+		idMutex.Lock()
+		args := JobArgs{WorkerID: workerID}
+		idMutex.Unlock()
+
+		var j Job
+		if err = client.Call("Coordinator.NextJob", args, &j); err != nil {
+			if err.Error() != errUnknownWorker {
+				break
+			}
+
+			// The coordinator gave up on us, most likely because we were
+			// simulating for longer than the heartbeat timeout
+			if err = reregister(args.WorkerID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !j.Available {
+			continue
+		}
+
+		// Submit as whoever we are now, we may have registered again while simulating
+		fitness := w.simulate(j)
+		idMutex.Lock()
+		result := Result{WorkerID: workerID, JobID: j.ID, Fitness: fitness}
+		idMutex.Unlock()
+		err = client.Call("Coordinator.SubmitResult", result, &ResultReply{})
+		if err != nil {
+			break
+		}
+	}
+
+	w.endGeneration()
+
+	if w.stopped() {
+		return nil
+	}
+	return err
+}
+
+func (w *Worker) register(client *rpc.Client) (int, time.Duration, error) {
+	var reply RegisterReply
+	if err := client.Call("Coordinator.Register", RegisterArgs{Name: w.Name}, &reply); err != nil {
+		return 0, 0, err
+	}
+	return reply.WorkerID, reply.HeartbeatInterval, nil
+}
+
+func (w *Worker) simulate(j Job) int {
+	if !w.simulating || j.Generation != w.generation {
+		w.endGeneration()
+		w.Simulator.OnBeginSimulation()
+		w.generation = j.Generation
+		w.simulating = true
+	}
+
+	g := goga.NewGenome(bitsToBitset(j.Bits))
+	w.Simulator.Simulate(g)
+	return g.GetFitness()
+}
+
+func (w *Worker) endGeneration() {
+	if w.simulating {
+		w.Simulator.OnEndSimulation()
+		w.simulating = false
+	}
+}
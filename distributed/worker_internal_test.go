@@ -0,0 +1,68 @@
+package distributed
+
+import (
+	"time"
+
+	"github.com/tomcraven/goga"
+	"gopkg.in/check.v1"
+)
+
+// WorkerSuite is an internal test so the coordinator can be made to give up
+// on a worker while it is still sending heartbeats
+type WorkerSuite struct {
+}
+
+var _ = check.Suite(&WorkerSuite{})
+
+type blockingSimulator struct {
+	goga.NullSimulator
+	started chan struct{}
+	release chan struct{}
+}
+
+func (bs *blockingSimulator) Simulate(g goga.Genome) {
+	close(bs.started)
+	<-bs.release
+	g.SetFitness(1)
+}
+
+func (s *WorkerSuite) TestShouldRegisterAgainWhenItsHeartbeatIsUnknown(t *check.C) {
+	c := NewCoordinator()
+	c.HeartbeatTimeout = 150 * time.Millisecond
+	c.PollTimeout = 50 * time.Millisecond
+	t.Assert(c.Listen("127.0.0.1:0"), check.IsNil)
+	defer c.Close()
+
+	simulator := &blockingSimulator{started: make(chan struct{}), release: make(chan struct{})}
+	w := NewWorker(simulator)
+	done := make(chan struct{})
+	go func() {
+		w.Run(c.Addr().String())
+		close(done)
+	}()
+
+	g := goga.NewGenome(goga.Bitset{})
+	simulated := make(chan struct{})
+	go func() {
+		c.Simulate(g)
+		close(simulated)
+	}()
+	<-simulator.started
+
+	// Give up on the worker while it is simulating, it only hears about it from its heartbeat
+	c.reapWorkersOnce(time.Now().Add(time.Hour))
+	t.Assert(c.NumWorkers(), check.Equals, 0)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for c.NumWorkers() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	t.Assert(c.NumWorkers(), check.Equals, 1)
+
+	close(simulator.release)
+	<-simulated
+	t.Assert(g.GetFitness(), check.Equals, 1)
+
+	w.Stop()
+	<-done
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tomcraven/goga"
+	"github.com/tomcraven/goga/distributed"
+)
+
+// A string matcher where every genome is simulated by a worker connected
+// over TCP. By default the coordinator and several workers all run in this
+// process on localhost, alternatively run one process with -workers 0 and
+// others with -join <address> to spread the work over several processes.
+
+var (
+	targetString = "abcdefghijklmnopqrstuvwxyz"
+	targetLength = len(targetString) * 8
+)
+
+const (
+	populationSize = 600
+)
+
+type stringMaterSimulator struct {
+}
+
+func (sms *stringMaterSimulator) OnBeginSimulation() {
+}
+func (sms *stringMaterSimulator) OnEndSimulation() {
+}
+func (sms *stringMaterSimulator) Simulate(g goga.Genome) {
+	bits := g.GetBits()
+	for i, character := range targetString {
+		for j := 0; j < 8; j++ {
+			targetBit := character & (1 << uint(j))
+			bit := bits.Get((i * 8) + j)
+			if targetBit != 0 && bit == 1 {
+				g.SetFitness(g.GetFitness() + 1)
+			} else if targetBit == 0 && bit == 0 {
+				g.SetFitness(g.GetFitness() + 1)
+			}
+		}
+	}
+
+	// Pretend this is an expensive simulation
+	time.Sleep(100 * time.Microsecond)
+}
+func (sms *stringMaterSimulator) ExitFunc(g goga.Genome) bool {
+	return g.GetFitness() == targetLength
+}
+
+type myEliteConsumer struct {
+	currentIter int
+	coordinator *distributed.Coordinator
+}
+
+func (ec *myEliteConsumer) OnElite(g goga.Genome) {
+	ec.currentIter++
+	fmt.Println(ec.currentIter, "\t", ec.coordinator.NumWorkers(), "workers\t", g.GetFitness())
+}
+
+func runWorker(address string) {
+	worker := distributed.NewWorker(&stringMaterSimulator{})
+	if err := worker.Run(address); err != nil {
+		log.Println("worker stopped:", err)
+	}
+}
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:0", "address for the coordinator to listen on")
+	join := flag.String("join", "", "run only a worker, connecting to the coordinator at this address")
+	numWorkers := flag.Int("workers", 4, "number of workers to run in this process")
+	flag.Parse()
+
+	if *join != "" {
+		runWorker(*join)
+		return
+	}
+
+	coordinator := distributed.NewCoordinator()
+	coordinator.Exit = (&stringMaterSimulator{}).ExitFunc
+	if err := coordinator.Listen(*listen); err != nil {
+		log.Fatal(err)
+	}
+	defer coordinator.Close()
+	fmt.Println("coordinator listening on", coordinator.Addr())
+
+	for i := 0; i < *numWorkers; i++ {
+		go runWorker(coordinator.Addr().String())
+	}
+
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = coordinator
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(targetLength)
+	genAlgo.EliteConsumer = &myEliteConsumer{coordinator: coordinator}
+	genAlgo.Mater = goga.NewMater(
+		[]goga.MaterFunctionProbability{
+			{P: 1.0, F: goga.TwoPointCrossover},
+			{P: 1.0, F: goga.Mutate},
+			{P: 1.0, F: goga.UniformCrossover, UseElite: true},
+		},
+	)
+	genAlgo.Selector = goga.NewSelector(
+		[]goga.SelectorFunctionProbability{
+			{P: 1.0, F: goga.Roulette},
+		},
+	)
+
+	// Keep a few genomes queued per worker so none of them wait on the network
+	parallelSimulations := 4 * *numWorkers
+	if parallelSimulations == 0 {
+		parallelSimulations = 64
+	}
+	genAlgo.Init(populationSize, parallelSimulations)
+
+	startTime := time.Now()
+	genAlgo.Simulate()
+	fmt.Println(time.Since(startTime))
+}
@@ -16,29 +16,31 @@ func createImageFromBitset(bits *goga.Bitset) draw.Image {
 	draw.Draw(newImage, newImage.Bounds(), &image.Uniform{color.RGBA{0, 0, 0, 255}}, image.ZP, draw.Over)
 
 	for i := 0; i < bits.GetSize()/largestShapeBits; i++ {
-		shapeBitset := bits.Slice(i*largestShapeBits, largestShapeBits)
+		offset := i * largestShapeBits
 
-		shapeType := shapeBitset.Get(0)
+		shapeType := bits.Get(offset)
 		if shapeType == 0 {
-			rectBitset := shapeBitset.Slice(1, bitsPerRect)
-			parsedBits := rectBitsetFormat.Process(&rectBitset)
+			fields, err := rectBitsetFormat.ProcessFieldsAt(bits, offset+bitsToDescribeWhichShape)
+			if err != nil {
+				panic(err)
+			}
 
 			colour := color.RGBA{
-				uint8(parsedBits[4]),
-				uint8(parsedBits[5]),
-				uint8(parsedBits[6]),
+				uint8(fields[4].(uint64)),
+				uint8(fields[5].(uint64)),
+				uint8(fields[6].(uint64)),
 				255,
 			}
 
 			alpha := color.RGBA{
 				255, 255, 255,
-				uint8(parsedBits[7]),
+				uint8(fields[7].(uint64)),
 			}
 
-			x1 := int((float64(parsedBits[0]) / float64(maxBoxCornerCoordinateNumber)) * float64(inputImageBounds.Max.X))
-			y1 := int((float64(parsedBits[1]) / float64(maxBoxCornerCoordinateNumber)) * float64(inputImageBounds.Max.Y))
-			x2 := int((float64(parsedBits[2]) / float64(maxBoxCornerCoordinateNumber)) * float64(inputImageBounds.Max.X))
-			y2 := int((float64(parsedBits[3]) / float64(maxBoxCornerCoordinateNumber)) * float64(inputImageBounds.Max.Y))
+			x1 := int(fields[0].(float64))
+			y1 := int(fields[1].(float64))
+			x2 := int(fields[2].(float64))
+			y2 := int(fields[3].(float64))
 
 			draw.DrawMask(newImage, image.Rect(x1, y1, x2, y2),
 				&image.Uniform{colour}, image.ZP,
@@ -46,36 +48,23 @@ func createImageFromBitset(bits *goga.Bitset) draw.Image {
 				draw.Over)
 
 		} else {
-			circleBitset := shapeBitset.Slice(1, bitsPerCircle)
-			parsedBits := circleBitsetFormat.Process(&circleBitset)
+			fields, err := circleBitsetFormat.ProcessFieldsAt(bits, offset+bitsToDescribeWhichShape)
+			if err != nil {
+				panic(err)
+			}
 
 			colour := color.RGBA{
-				uint8(parsedBits[3]),
-				uint8(parsedBits[4]),
-				uint8(parsedBits[5]),
+				uint8(fields[3].(uint64)),
+				uint8(fields[4].(uint64)),
+				uint8(fields[5].(uint64)),
 				255,
 			}
 
-			normalisedX := float64(parsedBits[0]) / float64(maxBoxCornerCoordinateNumber)
-			normalisedY := float64(parsedBits[1]) / float64(maxBoxCornerCoordinateNumber)
-
-			xMin := float64(-inputImageBounds.Max.X)
-			yMin := float64(-inputImageBounds.Max.Y)
-
-			xMax := float64(inputImageBounds.Max.X + inputImageBounds.Max.X)
-			yMax := float64(inputImageBounds.Max.Y + inputImageBounds.Max.Y)
-
-			xRange := xMax - xMin
-			yRange := yMax - yMin
-
-			x := int(xMin + (normalisedX * xRange))
-			y := int(yMin + (normalisedY * yRange))
-
-			normalisedR := float64(parsedBits[2]) / float64(maxBoxCornerCoordinateNumber)
-			maxR := math.Max(float64(inputImageBounds.Max.X), float64(inputImageBounds.Max.Y))
-			r := int(normalisedR*maxR) / maxCircleRadiusFactor
+			x := int(fields[0].(float64))
+			y := int(fields[1].(float64))
+			r := int(fields[2].(float64))
 
-			c := circle{image.Point{x, y}, r, uint8(parsedBits[6])}
+			c := circle{image.Point{x, y}, r, uint8(fields[6].(uint64))}
 
 			draw.DrawMask(newImage, inputImageBounds,
 				&image.Uniform{colour}, image.ZP,
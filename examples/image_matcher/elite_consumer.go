@@ -19,15 +19,17 @@ type myEliteConsumer struct {
 }
 
 func (ec *myEliteConsumer) OnElite(g goga.Genome) {
-	bits := g.GetBits()
-	newImage := createImageFromBitset(bits)
+	eliteImage := images.Get(g).(draw.Image)
 
 	// Output elite
 	outputImageFile, _ := os.Create("elite.png")
-	png.Encode(outputImageFile, newImage)
+	png.Encode(outputImageFile, eliteImage)
 	outputImageFile.Close()
 
-	// Output elite with input image blended over the top
+	// Output elite with input image blended over the top, drawn on a copy
+	// as the elite's image is shared with the phenotype cache
+	newImage := image.NewRGBA(eliteImage.Bounds())
+	draw.Draw(newImage, newImage.Bounds(), eliteImage, eliteImage.Bounds().Min, draw.Src)
 	outputImageFileAlphaBlended, _ := os.Create("elite_with_original.png")
 	draw.DrawMask(newImage, newImage.Bounds(),
 		inputImage, image.ZP,
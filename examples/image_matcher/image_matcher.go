@@ -3,18 +3,22 @@ package main
 import (
 	"fmt"
 	"image"
+	"image/draw"
 	_ "image/jpeg"
-	"math/rand"
+	"math"
+	"net/http"
 	"os"
 	"runtime"
 	"time"
 
 	"github.com/tomcraven/goga"
+	"github.com/tomcraven/goga/dashboard"
 )
 
 const (
 	// Fiddle with these
-	numShapes               = 10
+	numShapes               = 10 // genomes start with this many shapes
+	maxShapes               = 50 // and can grow to this many
 	populationSize          = 10
 	maxIterations           = 9999999
 	bitsPerCoordinateNumber = 9
@@ -22,11 +26,10 @@ const (
 	maxCircleRadiusFactor   = 3 // larger == smaller max circle size relative to image dimensions
 
 	// Don't fiddle with these...
-	maxBoxCornerCoordinateNumber = (1 << bitsPerCoordinateNumber) - 1
-	bitsPerColourChannel         = 8 // 0 - 255
-	bitsPerRect                  = (bitsPerCoordinateNumber * 4) + (bitsPerColourChannel * 4)
-	bitsPerCircle                = (bitsPerCoordinateNumber * 3) + (bitsPerColourChannel * 4)
-	bitsToDescribeWhichShape     = 1
+	bitsPerColourChannel     = 8 // 0 - 255
+	bitsPerRect              = (bitsPerCoordinateNumber * 4) + (bitsPerColourChannel * 4)
+	bitsPerCircle            = (bitsPerCoordinateNumber * 3) + (bitsPerColourChannel * 4)
+	bitsToDescribeWhichShape = 1
 )
 
 type imageMatcherSimulator struct {
@@ -39,8 +42,7 @@ func (simulator *imageMatcherSimulator) OnEndSimulation() {
 	simulator.totalIterations++
 }
 func (simulator *imageMatcherSimulator) Simulate(g goga.Genome) {
-	bits := g.GetBits()
-	newImage := createImageFromBitset(bits)
+	newImage := images.Get(g).(draw.Image)
 
 	inputImageBounds := inputImage.Bounds()
 	fitness := 0.0
@@ -60,18 +62,6 @@ func (simulator *imageMatcherSimulator) ExitFunc(g goga.Genome) bool {
 	return simulator.totalIterations >= maxIterations
 }
 
-type myBitsetCreate struct {
-}
-
-func (bc *myBitsetCreate) Go() goga.Bitset {
-	b := goga.Bitset{}
-	b.Create(numShapes * largestShapeBits)
-	for i := 0; i < b.GetSize(); i++ {
-		b.Set(i, rand.Intn(2))
-	}
-	return b
-}
-
 var (
 	largestShapeBits   int
 	totalBitsPerGenome int
@@ -80,6 +70,11 @@ var (
 
 	circleBitsetFormat goga.BitsetParse
 	rectBitsetFormat   goga.BitsetParse
+
+	// Each genome's image is drawn once when it is simulated and reused by the elite consumer
+	images = goga.NewPhenotypeCache("image", func(g goga.Genome) interface{} {
+		return createImageFromBitset(g.GetBits())
+	})
 )
 
 func init() {
@@ -107,25 +102,48 @@ func main() {
 	// Get the input image
 	inputImage = getImageFromFile(os.Args[1])
 
+	inputImageBounds := inputImage.Bounds()
+	width, height := float64(inputImageBounds.Max.X), float64(inputImageBounds.Max.Y)
+	colourChannel := goga.Field{Bits: bitsPerColourChannel}
+
+	// Rect corners are anywhere within the image
 	rectBitsetFormat = goga.CreateBitsetParse()
-	rectBitsetFormat.SetFormat([]int{
-		bitsPerCoordinateNumber, bitsPerCoordinateNumber, bitsPerCoordinateNumber, bitsPerCoordinateNumber,
-		bitsPerColourChannel, bitsPerColourChannel, bitsPerColourChannel, bitsPerColourChannel,
+	err := rectBitsetFormat.SetFields([]goga.Field{
+		{Bits: bitsPerCoordinateNumber, Encoding: goga.EncodingScaledFloat, Min: 0, Max: width},
+		{Bits: bitsPerCoordinateNumber, Encoding: goga.EncodingScaledFloat, Min: 0, Max: height},
+		{Bits: bitsPerCoordinateNumber, Encoding: goga.EncodingScaledFloat, Min: 0, Max: width},
+		{Bits: bitsPerCoordinateNumber, Encoding: goga.EncodingScaledFloat, Min: 0, Max: height},
+		colourChannel, colourChannel, colourChannel, colourChannel,
 	})
+	if err != nil {
+		panic(err)
+	}
 
+	// Circle centres can be up to an image's width or height outside of it
+	maxRadius := math.Max(width, height) / maxCircleRadiusFactor
 	circleBitsetFormat = goga.CreateBitsetParse()
-	circleBitsetFormat.SetFormat([]int{
-		bitsPerCoordinateNumber, bitsPerCoordinateNumber, bitsPerCoordinateNumber,
-		bitsPerColourChannel, bitsPerColourChannel, bitsPerColourChannel, bitsPerColourChannel,
+	err = circleBitsetFormat.SetFields([]goga.Field{
+		{Bits: bitsPerCoordinateNumber, Encoding: goga.EncodingScaledFloat, Min: -width, Max: 2 * width},
+		{Bits: bitsPerCoordinateNumber, Encoding: goga.EncodingScaledFloat, Min: -height, Max: 2 * height},
+		{Bits: bitsPerCoordinateNumber, Encoding: goga.EncodingScaledFloat, Min: 0, Max: maxRadius},
+		colourChannel, colourChannel, colourChannel, colourChannel,
 	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Each shape is a block that can be added or removed
+	shapes := goga.LengthConfig{BlockSize: largestShapeBits, MinBlocks: 1, MaxBlocks: maxShapes}
 
 	genAlgo := goga.NewGeneticAlgorithm()
 	genAlgo.Simulator = &imageMatcherSimulator{}
-	genAlgo.BitsetCreate = &myBitsetCreate{}
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(totalBitsPerGenome)
 	genAlgo.EliteConsumer = &myEliteConsumer{}
 	genAlgo.Mater = goga.NewMater(
 		[]goga.MaterFunctionProbability{
 			{P: 1.0, F: goga.UniformCrossover, UseElite: true},
+			{P: 0.05, F: shapes.BlockInsertion()},
+			{P: 0.05, F: shapes.BlockDeletion()},
 			{P: 1.0, F: goga.Mutate},
 			{P: 1.0, F: goga.Mutate},
 			{P: 1.0, F: goga.Mutate},
@@ -146,6 +164,18 @@ func main() {
 		},
 	)
 
+	// An optional second argument is the address to serve a dashboard of the run on, e.g. 127.0.0.1:8080
+	if len(os.Args) > 2 {
+		board := dashboard.New(dashboard.NewPNGRenderer(func(g goga.Genome) image.Image {
+			return images.Get(g).(image.Image)
+		}))
+		board.Controller = &genAlgo
+		genAlgo.Monitors = []goga.Monitor{board}
+		go func() {
+			fmt.Println(http.ListenAndServe(os.Args[2], board))
+		}()
+	}
+
 	genAlgo.Init(populationSize, parallelSimulations)
 
 	startTime := time.Now()
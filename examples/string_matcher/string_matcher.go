@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"math/rand"
 	"os"
 	"runtime"
 	"time"
@@ -35,18 +34,6 @@ func (sms *stringMaterSimulator) ExitFunc(g goga.Genome) bool {
 	return g.GetFitness() == targetLength
 }
 
-type myBitsetCreate struct {
-}
-
-func (bc *myBitsetCreate) Go() goga.Bitset {
-	b := goga.Bitset{}
-	b.Create(targetLength)
-	for i := 0; i < targetLength; i++ {
-		b.Set(i, rand.Intn(2))
-	}
-	return b
-}
-
 type myEliteConsumer struct {
 	currentIter int
 }
@@ -63,7 +50,7 @@ func (ec *myEliteConsumer) OnElite(g goga.Genome) {
 				c |= 1 << uint(j)
 			}
 		}
-		genomeString += string(c)
+		genomeString += string(rune(c))
 	}
 
 	fmt.Println(ec.currentIter, "\t", genomeString, "\t", g.GetFitness())
@@ -93,7 +80,7 @@ func main() {
 	genAlgo := goga.NewGeneticAlgorithm()
 
 	genAlgo.Simulator = &stringMaterSimulator{}
-	genAlgo.BitsetCreate = &myBitsetCreate{}
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(targetLength)
 	genAlgo.EliteConsumer = &myEliteConsumer{}
 	genAlgo.Mater = goga.NewMater(
 		[]goga.MaterFunctionProbability{
@@ -1,6 +1,7 @@
 package goga
 
 import (
+	"math"
 	"sync"
 	"time"
 )
@@ -11,24 +12,94 @@ import (
 // * EliteConsumer - an optional class that accepts the 'elite' of each population generation
 // * Simulator - a simulation component used to score each genome in each generation
 // * BitsetCreate - used to create the initial population of genomes
+// * GenomeCreate - an optional class used in place of BitsetCreate for genomes that hold more than a bitset
+// * HallOfFame - an optional class that records the best genomes over every generation
+// * Niching - an optional class that adjusts fitness before selection to preserve diversity
+// * Crowding - an optional class that decides whether offspring replace their parents
 type GeneticAlgorithm struct {
 	Mater         Mater
 	EliteConsumer EliteConsumer
 	Simulator     Simulator
 	Selector      Selector
 	BitsetCreate  BitsetCreate
+	GenomeCreate  GenomeCreate
+	HallOfFame    HallOfFame
+	Niching       Niching
+	Crowding      Crowding
 
-	populationSize          int
-	population              []Genome
-	totalFitness            int
-	genomeSimulationChannel chan Genome
-	exitFunc                func(Genome) bool
-	waitGroup               *sync.WaitGroup
-	parallelSimulations     int
+	// HallOfFameStagnation is the number of generations the elite's fitness
+	// can go without improving before the hall of fame is injected back into
+	// the population, 0 never injects
+	HallOfFameStagnation int
+
+	// Terminator, if set, decides when the algorithm stops in place of the
+	// Simulator's ExitFunc
+	Terminator Terminator
+
+	// SteadyState breeds and simulates one genome at a time rather than a
+	// whole population per generation, see simulateSteadyState
+	SteadyState bool
+
+	// Replacement picks which member of the population a newly simulated
+	// genome replaces in steady state mode, ReplaceWorst is used if nil
+	Replacement ReplacementFunc
+
+	// ParallelBreeding selects and mates genomes on the simulation goroutines
+	// rather than one at a time on the goroutine that called Simulate.
+	// The Selector and Mater must be safe for concurrent use when this is set
+	ParallelBreeding bool
+
+	// Reproducer, if set, breeds offspring in place of the Mater, so
+	// that offspring can have any number of parents
+	Reproducer Reproducer
+
+	// NumOffspring is the number of offspring, λ, bred each generation in
+	// generational mode, the population size, μ, if 0. When λ differs from μ
+	// and Survivors is not set the next generation is picked by CommaSurvival
+	NumOffspring int
+
+	// KeepParents makes the next generation the fittest μ of the offspring and
+	// the current generation together, using PlusSurvival, when Survivors is not set
+	KeepParents bool
+
+	// Survivors, if set, picks the next generation from the current one and its
+	// offspring in generational mode, independently of the Selector that picks
+	// parents. GenerationalSurvival is used if it is not set, see also NumOffspring
+	// and KeepParents
+	Survivors SurvivorFunc
+
+	// Convergence, if set, detects when the population has converged
+	// prematurely and how the algorithm responds to it
+	Convergence *Convergence
+
+	// Observers are told about the events of a run, such as convergence
+	Observers []Observer
+
+	// LineageRecorder, if set, gives every genome that carries its Lineage
+	// an ID and records the lineage of each generation's population
+	LineageRecorder LineageRecorder
+
+	// Monitors measure the run, they are told how long each simulation took
+	// and about each generation
+	Monitors []Monitor
+
+	populationSize      int
+	population          []Genome
+	totalFitness        int
+	exitFunc            func(Genome) bool
+	pool                *workerPool
+	parallelSimulations int
+	runState            RunState
+	terminationReason   string
+	stateMutex          sync.Mutex
+	convergence         convergenceState
+	ages                []int
+	controlOnce         sync.Once
+	control             *runControl
 }
 
 // NewGeneticAlgorithm returns a new GeneticAlgorithm structure with null implementations of
-// EliteConsumer, Mater, Simulator, Selector and BitsetCreate
+// EliteConsumer, Mater, Simulator, Selector, BitsetCreate and HallOfFame
 func NewGeneticAlgorithm() GeneticAlgorithm {
 	return GeneticAlgorithm{
 		EliteConsumer: &NullEliteConsumer{},
@@ -36,55 +107,110 @@ func NewGeneticAlgorithm() GeneticAlgorithm {
 		Simulator:     &NullSimulator{},
 		Selector:      &NullSelector{},
 		BitsetCreate:  &NullBitsetCreate{},
+		HallOfFame:    &NullHallOfFame{},
+		Niching:       &NullNiching{},
+		Crowding:      &NullCrowding{},
 	}
 }
 
-func (ga *GeneticAlgorithm) createPopulation() []Genome {
+func (ga *GeneticAlgorithm) createPopulation(seeds []Bitset) []Genome {
 	ret := make([]Genome, ga.populationSize)
 	for i := 0; i < ga.populationSize; i++ {
-		ret[i] = NewGenome(ga.BitsetCreate.Go())
+		if i < len(seeds) {
+			ret[i] = NewGenome(seeds[i].CreateCopy())
+		} else {
+			ret[i] = ga.createGenome()
+		}
 	}
 	return ret
 }
 
+// createGenome returns a new genome from GenomeCreate, or BitsetCreate if it is not set
+func (ga *GeneticAlgorithm) createGenome() Genome {
+	if ga.GenomeCreate != nil {
+		return ga.GenomeCreate.Go()
+	}
+	return NewGenome(ga.BitsetCreate.Go())
+}
+
 // Init initialises internal components, sets up the population size
 // and number of parallel simulations
 func (ga *GeneticAlgorithm) Init(populationSize, parallelSimulations int) {
+	ga.InitWithBitsets(populationSize, parallelSimulations, nil, 0)
+}
+
+// InitWithBitsets initialises the algorithm as Init does but with up to
+// 'fraction' of the population, between 0 and 1, made up of copies of 'bitsets'
+// and the rest created by BitsetCreate. The bitsets are used in order, so the
+// fittest should come first if there are more than fit in the fraction.
+// This allows optimisation to continue from the bitsets of a previous run, for
+// example read with ReadBitsetsFile or taken from a HallOfFame with GetBitsets,
+// after the simulator has changed. The loaded genomes are simulated again
+func (ga *GeneticAlgorithm) InitWithBitsets(populationSize, parallelSimulations int, bitsets []Bitset, fraction float64) {
+	numLoaded := int(math.Round(fraction * float64(populationSize)))
+	if numLoaded > len(bitsets) {
+		numLoaded = len(bitsets)
+	}
+	if numLoaded < 0 {
+		numLoaded = 0
+	}
+
 	ga.populationSize = populationSize
-	ga.population = ga.createPopulation()
+	ga.population = ga.createPopulation(bitsets[:numLoaded])
 	ga.parallelSimulations = parallelSimulations
-
-	ga.waitGroup = new(sync.WaitGroup)
 }
 
 func (ga *GeneticAlgorithm) beginSimulation() {
 	ga.Simulator.OnBeginSimulation()
 	ga.totalFitness = 0
+}
 
-	ga.genomeSimulationChannel = make(chan Genome)
-
-	// todo: make configurable
-	for i := 0; i < ga.parallelSimulations; i++ {
-		go func(genomeSimulationChannel chan Genome,
-			waitGroup *sync.WaitGroup, simulator Simulator) {
-
-			for genome := range genomeSimulationChannel {
-				defer waitGroup.Done()
-				simulator.Simulate(genome)
+// simulateFunc returns a function that simulates a genome, timing it for the
+// Monitors, and then, if the Mater, or Reproducer, implements MaterFeedback,
+// passes it back to it
+func (ga *GeneticAlgorithm) simulateFunc() func(Genome) {
+	simulate := ga.Simulator.Simulate
+	if monitors := ga.Monitors; len(monitors) > 0 {
+		simulator := ga.Simulator
+		simulate = func(g Genome) {
+			start := time.Now()
+			simulator.Simulate(g)
+			duration := time.Since(start)
+			for _, monitor := range monitors {
+				monitor.OnSimulated(g, duration)
 			}
-		}(ga.genomeSimulationChannel, ga.waitGroup, ga.Simulator)
+		}
 	}
 
-	ga.waitGroup.Add(ga.populationSize)
+	feedback := ga.materFeedback()
+	if feedback == nil {
+		return simulate
+	}
+	return func(g Genome) {
+		simulate(g)
+		feedback.OnSimulated(g)
+	}
+}
+
+// materFeedback returns the Mater, or Reproducer, if it implements MaterFeedback, otherwise nil
+func (ga *GeneticAlgorithm) materFeedback() MaterFeedback {
+	var breeder interface{} = ga.Mater
+	if ga.Reproducer != nil {
+		breeder = ga.Reproducer
+	}
+	feedback, _ := breeder.(MaterFeedback)
+	return feedback
 }
 
 func (ga *GeneticAlgorithm) onNewGenomeToSimulate(g Genome) {
-	ga.genomeSimulationChannel <- g
+	simulate := ga.simulateFunc()
+	ga.pool.submit(func() {
+		simulate(g)
+	})
 }
 
 func (ga *GeneticAlgorithm) syncSimulatingGenomes() {
-	close(ga.genomeSimulationChannel)
-	ga.waitGroup.Wait()
+	ga.pool.wait()
 }
 
 func (ga *GeneticAlgorithm) getElite() Genome {
@@ -97,6 +223,56 @@ func (ga *GeneticAlgorithm) getElite() Genome {
 	return ret
 }
 
+func (ga *GeneticAlgorithm) resetRunState() {
+	ga.stateMutex.Lock()
+	defer ga.stateMutex.Unlock()
+
+	ga.runState = RunState{
+		StartTime:           time.Now(),
+		ParallelSimulations: max(ga.parallelSimulations, 1),
+	}
+	ga.terminationReason = ""
+}
+
+func (ga *GeneticAlgorithm) onGenerationSimulated(numSimulated int) {
+	ga.stateMutex.Lock()
+	defer ga.stateMutex.Unlock()
+
+	state := &ga.runState
+	state.Evaluations += numSimulated
+
+	elite := ga.getElite()
+	if state.Generation == 0 || elite.GetFitness() > state.BestFitness {
+		state.BestFitness = elite.GetFitness()
+		state.StagnantGenerations = 0
+	} else {
+		state.StagnantGenerations++
+	}
+
+	state.Generation++
+	state.Elite = elite
+	state.EliteHistory = append(state.EliteHistory, elite.GetFitness())
+	state.Elapsed = time.Since(state.StartTime)
+}
+
+// GetRunState returns a snapshot of the state of the current, or most
+// recent, run. It is safe to call while the algorithm is running
+func (ga *GeneticAlgorithm) GetRunState() RunState {
+	ga.stateMutex.Lock()
+	defer ga.stateMutex.Unlock()
+
+	ret := ga.runState
+	ret.EliteHistory = append([]int(nil), ga.runState.EliteHistory...)
+	return ret
+}
+
+// GetTerminationReason returns why the most recent run stopped
+func (ga *GeneticAlgorithm) GetTerminationReason() string {
+	ga.stateMutex.Lock()
+	defer ga.stateMutex.Unlock()
+	return ga.terminationReason
+}
+
 // SimulateUntil simulates a population until 'exitFunc' returns true
 // The 'exitFunc' is passed the elite of each population and should return true
 // if the elite reaches a certain criteria (e.g. fitness above a certain threshold)
@@ -106,19 +282,72 @@ func (ga *GeneticAlgorithm) SimulateUntil(exitFunc func(Genome) bool) bool {
 }
 
 func (ga *GeneticAlgorithm) shouldExit(elite Genome) bool {
-	if ga.exitFunc == nil {
-		return ga.Simulator.ExitFunc(elite)
+	exit, reason := false, ""
+	if ga.exitFunc != nil {
+		exit, reason = ga.exitFunc(elite), "exit function returned true"
+	} else if ga.Terminator != nil {
+		// Only this goroutine writes the run state so no lock is needed to read it
+		state := ga.runState
+		exit, reason = ga.Terminator.Terminate(&state)
+	} else {
+		exit, reason = ga.Simulator.ExitFunc(elite), "simulator exit function returned true"
+	}
+
+	if exit {
+		ga.stateMutex.Lock()
+		ga.terminationReason = reason
+		ga.stateMutex.Unlock()
 	}
-	return ga.exitFunc(elite)
+	return exit
 }
 
-// Simulate runs the genetic algorithm
+// onGeneration is called once a generation's worth of genomes have been
+// simulated, it passes the elite on and returns true if the algorithm should exit
+func (ga *GeneticAlgorithm) onGeneration(numSimulated int) bool {
+	ga.onGenerationSimulated(numSimulated)
+	ga.monitorGeneration()
+	ga.recordLineage()
+	if ga.HallOfFame != nil {
+		ga.HallOfFame.Update(ga.population)
+	}
+
+	elite := ga.runState.Elite
+	ga.reproducer().OnElite(elite)
+	ga.EliteConsumer.OnElite(elite)
+	if ga.shouldExit(elite) {
+		return true
+	}
+
+	stagnant := ga.runState.StagnantGenerations
+	if ga.HallOfFame != nil && ga.HallOfFameStagnation > 0 && stagnant > 0 && stagnant%ga.HallOfFameStagnation == 0 {
+		ga.HallOfFame.Inject(ga.population)
+	}
+
+	ga.checkConvergence()
+	return ga.checkControl()
+}
+
+// Simulate runs the genetic algorithm, it can be paused, stepped and
+// stopped from other goroutines while it runs, see Pause, Step and Stop
 func (ga *GeneticAlgorithm) Simulate() bool {
+	ga.runControl().beginRun()
+	return ga.simulate()
+}
+
+// simulate runs the genetic algorithm once the run has begun
+func (ga *GeneticAlgorithm) simulate() bool {
+	defer ga.runControl().endRun()
 
 	if ga.populationSize == 0 {
 		return false
 	}
 
+	ga.resetRunState()
+	ga.convergence = convergenceState{}
+	ga.ages = make([]int, ga.populationSize)
+	ga.pool = newWorkerPool(ga.parallelSimulations)
+	defer ga.pool.close()
+
 	ga.beginSimulation()
 	for i := 0; i < ga.populationSize; i++ {
 		ga.onNewGenomeToSimulate(ga.population[i])
@@ -126,39 +355,166 @@ func (ga *GeneticAlgorithm) Simulate() bool {
 	ga.syncSimulatingGenomes()
 	ga.Simulator.OnEndSimulation()
 
-	for {
-		elite := ga.getElite()
-		ga.Mater.OnElite(elite)
-		ga.EliteConsumer.OnElite(elite)
-		if ga.shouldExit(elite) {
-			break
-		}
+	if ga.SteadyState {
+		ga.simulateSteadyState()
+	} else {
+		ga.simulateGenerational()
+	}
 
-		time.Sleep(1 * time.Microsecond)
+	return true
+}
 
+func (ga *GeneticAlgorithm) simulateGenerational() {
+	numSimulated := ga.populationSize
+	for !ga.onGeneration(numSimulated) {
+		rawFitness := ga.applyNiching()
 		ga.beginSimulation()
+		ga.totalFitness = sumFitness(ga.population)
 
-		newPopulation := ga.createPopulation()
-		for i := 0; i < ga.populationSize; i += 2 {
-			g1 := ga.Selector.Go(ga.population, ga.totalFitness)
-			g2 := ga.Selector.Go(ga.population, ga.totalFitness)
-
-			g3, g4 := ga.Mater.Go(g1, g2)
-
-			newPopulation[i] = g3
+		// Children are simulated as soon as they are bred so breeding the
+		// rest of the generation overlaps with their simulation
+		numSimulated = ga.numOffspring()
+		newPopulation := make([]Genome, numSimulated)
+		parents := make([]offspringParent, numSimulated)
+		toCreate := min(ga.takeToCreate(), numSimulated)
+		for i := 0; i < toCreate; i++ {
+			newPopulation[i] = ga.createGenome()
 			ga.onNewGenomeToSimulate(newPopulation[i])
+		}
 
-			if (i + 1) < ga.populationSize {
-				newPopulation[i+1] = g4
-				ga.onNewGenomeToSimulate(newPopulation[i+1])
+		reproducer := ga.reproducer()
+		for i := toCreate; i < numSimulated; i += reproducer.NumOffspring() {
+			if ga.ParallelBreeding {
+				ga.breedInParallel(reproducer, newPopulation, parents, i)
+			} else {
+				ga.breed(reproducer, newPopulation, parents, i)
 			}
 		}
-		ga.population = newPopulation
 		ga.syncSimulatingGenomes()
+
+		for i, g := range ga.population {
+			g.SetFitness(rawFitness[i])
+		}
+		ga.crowd(newPopulation, parents)
+		ga.population = ga.survivors(newPopulation)
 		ga.Simulator.OnEndSimulation()
 	}
+}
 
-	return true
+// applyNiching adjusts the fitness of the population with Niching,
+// returning the fitness of each genome from before it was adjusted
+func (ga *GeneticAlgorithm) applyNiching() []int {
+	rawFitness := make([]int, len(ga.population))
+	for i, g := range ga.population {
+		rawFitness[i] = g.GetFitness()
+	}
+	if ga.Niching != nil {
+		ga.Niching.Go(ga.population)
+	}
+	return rawFitness
+}
+
+func sumFitness(population []Genome) int {
+	total := 0
+	for _, g := range population {
+		total += g.GetFitness()
+	}
+	if total < 0 {
+		return 0
+	}
+	return total
+}
+
+// offspringParent - the parent an offspring competes with under Crowding and the
+// index of the first offspring of its family, so offspring are only crowded with their siblings
+type offspringParent struct {
+	parent Genome
+	family int
+}
+
+// crowd replaces each pair of sibling offspring in 'newPopulation' with
+// the survivors of Crowding between them and their 'parents'
+func (ga *GeneticAlgorithm) crowd(newPopulation []Genome, parents []offspringParent) {
+	if ga.Crowding == nil {
+		return
+	}
+
+	for i := 0; i < len(newPopulation); {
+		p := parents[i]
+		if p.parent == nil {
+			// Created rather than bred, so there is no parent to compete with
+			i++
+			continue
+		}
+
+		if i+1 < len(newPopulation) && parents[i+1].parent != nil && parents[i+1].family == p.family {
+			newPopulation[i], newPopulation[i+1] = ga.Crowding.Go(p.parent, parents[i+1].parent, newPopulation[i], newPopulation[i+1])
+			i += 2
+		} else {
+			// The family has an odd number of offspring, or the rest didn't fit in the
+			// population, so the last child competes with its parent alone
+			newPopulation[i], _ = ga.Crowding.Go(p.parent, p.parent, newPopulation[i], newPopulation[i])
+			i++
+		}
+	}
+}
+
+// prepareOffspring returns a function that hypermutates, and gives a lineage
+// to, the offspring bred from 'selected' before they are simulated.
+// It is safe to call from any goroutine
+func (ga *GeneticAlgorithm) prepareOffspring() func(selected, offspring []Genome) {
+	rate := ga.hypermutationRate()
+	recorder, generation := ga.LineageRecorder, ga.runState.Generation+1
+	replacer, _ := ga.materFeedback().(offspringReplacer)
+	return func(selected, offspring []Genome) {
+		for i := range offspring {
+			mutated := hypermutate(offspring[i], rate)
+			if replacer != nil && mutated != offspring[i] {
+				replacer.replaceOffspring(offspring[i], mutated)
+			}
+			offspring[i] = mutated
+		}
+		if recorder != nil {
+			inheritLineage(recorder, offspring, selected, generation)
+		}
+	}
+}
+
+// placeOffspring puts as many of 'offspring' into 'newPopulation' from index 'i'
+// as fit, recording a parent and the family of each for Crowding, and returns those placed
+func placeOffspring(newPopulation []Genome, parents []offspringParent, i int, selected, offspring []Genome) []Genome {
+	offspring = offspring[:min(len(offspring), len(newPopulation)-i)]
+	for j := range offspring {
+		newPopulation[i+j] = offspring[j]
+		parents[i+j] = offspringParent{parent: selected[j%len(selected)], family: i}
+	}
+	return offspring
+}
+
+func (ga *GeneticAlgorithm) breed(reproducer Reproducer, newPopulation []Genome, parents []offspringParent, i int) {
+	selected := selectParents(ga.Selector, ga.population, ga.totalFitness, reproducer.NumParents())
+	offspring := reproduce(reproducer, selected)
+	ga.prepareOffspring()(selected, offspring)
+
+	for _, g := range placeOffspring(newPopulation, parents, i, selected, offspring) {
+		ga.onNewGenomeToSimulate(g)
+	}
+}
+
+// breedInParallel selects, mates and simulates a set of children on one of
+// the pool's goroutines, the population is not modified until every task has finished
+func (ga *GeneticAlgorithm) breedInParallel(reproducer Reproducer, newPopulation []Genome, parents []offspringParent, i int) {
+	population, totalFitness := ga.population, ga.totalFitness
+	selector, simulate, prepare := ga.Selector, ga.simulateFunc(), ga.prepareOffspring()
+	ga.pool.submit(func() {
+		selected := selectParents(selector, population, totalFitness, reproducer.NumParents())
+		offspring := reproduce(reproducer, selected)
+		prepare(selected, offspring)
+
+		for _, g := range placeOffspring(newPopulation, parents, i, selected, offspring) {
+			simulate(g)
+		}
+	})
 }
 
 // GetPopulation returns the population
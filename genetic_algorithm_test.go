@@ -7,6 +7,8 @@ import (
 	// "fmt"
 	"math/rand"
 	"sync"
+	"sync/atomic"
+	"testing"
 	"time"
 )
 
@@ -504,6 +506,65 @@ func (s *GeneticAlgorithmSuite) TestShouldCallIntoBitsetCreate(t *C) {
 	t.Assert(bitsetCreate.NumCalls, Equals, numGenomes)
 }
 
+type MyGenomeCreateCounter struct {
+	NumCalls int
+}
+
+func (gc *MyGenomeCreateCounter) Go() goga.Genome {
+	gc.NumCalls++
+	return goga.NewGenome(goga.Bitset{})
+}
+
+func (s *GeneticAlgorithmSuite) TestShouldPreferGenomeCreateToBitsetCreate(t *C) {
+
+	genAlgo := goga.NewGeneticAlgorithm()
+
+	bitsetCreate := MyBitsetCreateCounter{}
+	genAlgo.BitsetCreate = &bitsetCreate
+	genomeCreate := MyGenomeCreateCounter{}
+	genAlgo.GenomeCreate = &genomeCreate
+
+	genAlgo.Init(100, kNumThreads)
+
+	t.Assert(genomeCreate.NumCalls, Equals, 100)
+	t.Assert(bitsetCreate.NumCalls, Equals, 0)
+}
+
+func (s *GeneticAlgorithmSuite) TestShouldInitWithBitsets(t *C) {
+
+	genAlgo := goga.NewGeneticAlgorithm()
+
+	bitsetCreate := MyBitsetCreateCounter{}
+	genAlgo.BitsetCreate = &bitsetCreate
+
+	bitsets := []goga.Bitset{*helperBitset(t, "0101"), *helperBitset(t, "1100"), *helperBitset(t, "1111")}
+	genAlgo.InitWithBitsets(10, kNumThreads, bitsets, 0.2)
+
+	t.Assert(bitsetCreate.NumCalls, Equals, 8)
+	population := genAlgo.GetPopulation()
+	t.Assert(population[0].GetBits().String(), Equals, "0101")
+	t.Assert(population[1].GetBits().String(), Equals, "1100")
+
+	// The population holds copies of the bitsets
+	population[0].GetBits().Set(0, 1)
+	t.Assert(bitsets[0].String(), Equals, "0101")
+}
+
+func (s *GeneticAlgorithmSuite) TestShouldInitWithFewerBitsetsThanTheFraction(t *C) {
+
+	genAlgo := goga.NewGeneticAlgorithm()
+
+	bitsetCreate := MyBitsetCreateCounter{}
+	genAlgo.BitsetCreate = &bitsetCreate
+
+	bitsets := []goga.Bitset{*helperBitset(t, "0101")}
+	genAlgo.InitWithBitsets(10, kNumThreads, bitsets, 1)
+
+	t.Assert(bitsetCreate.NumCalls, Equals, 9)
+	t.Assert(genAlgo.GetPopulation()[0].GetBits().String(), Equals, "0101")
+	t.Assert(len(genAlgo.GetPopulation()), Equals, 10)
+}
+
 type MyMaterPassCache2 struct {
 	PassedGenomes  []goga.Genome
 	runningFitness int
@@ -584,3 +645,109 @@ func (s *GeneticAlgorithmSuite) TestShouldSimulateUsingSimulatorExitFunction(t *
 	t.Assert(ms.NumSimulateCalls, Equals, ms.NumBeginSimulationsUntilExit*populationSize)
 	t.Assert(ms.NumBeginSimulationCalls, Equals, ms.NumBeginSimulationsUntilExit)
 }
+
+func (s *GeneticAlgorithmSuite) TestShouldBreedInParallel(t *C) {
+	var numMaterCalls int64
+	mateFunc := func(a, b goga.Genome) (goga.Genome, goga.Genome) {
+		atomic.AddInt64(&numMaterCalls, 1)
+		return a, b
+	}
+
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.ParallelBreeding = true
+	genAlgo.Mater = goga.NewMater(
+		[]goga.MaterFunctionProbability{
+			{P: 1, F: mateFunc},
+		},
+	)
+
+	ms := MySimulatorCounter{}
+	genAlgo.Simulator = &ms
+
+	populationSize := 99
+	genAlgo.Init(populationSize, kNumThreads)
+
+	numIterations := 10
+	genAlgo.SimulateUntil(helperGenerateExitFunction(numIterations))
+
+	t.Assert(ms.NumCalls, Equals, numIterations*populationSize)
+	t.Assert(int(numMaterCalls), Equals, (numIterations-1)*((populationSize+1)/2))
+
+	population := genAlgo.GetPopulation()
+	t.Assert(population, HasLen, populationSize)
+	for _, g := range population {
+		t.Assert(g, NotNil)
+	}
+}
+
+type benchmarkSimulator struct {
+}
+
+func (bs *benchmarkSimulator) OnBeginSimulation() {
+}
+func (bs *benchmarkSimulator) Simulate(g goga.Genome) {
+	bits := g.GetBits()
+	fitness := 0
+	for repeat := 0; repeat < 10; repeat++ {
+		for i := 0; i < bits.GetSize(); i++ {
+			fitness += bits.Get(i) * (i % 7)
+		}
+	}
+	g.SetFitness(fitness / 10)
+}
+func (bs *benchmarkSimulator) OnEndSimulation() {
+}
+func (bs *benchmarkSimulator) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+type benchmarkBitsetCreate struct {
+}
+
+func (bc *benchmarkBitsetCreate) Go() goga.Bitset {
+	b := goga.Bitset{}
+	b.Create(256)
+	for i := 0; i < b.GetSize(); i++ {
+		b.Set(i, rand.Intn(2))
+	}
+	return b
+}
+
+func helperBenchmarkGeneticAlgorithm(b *testing.B, configure func(*goga.GeneticAlgorithm)) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &benchmarkSimulator{}
+	genAlgo.BitsetCreate = &benchmarkBitsetCreate{}
+	genAlgo.Mater = goga.NewMater(
+		[]goga.MaterFunctionProbability{
+			{P: 1.0, F: goga.TwoPointCrossover},
+			{P: 1.0, F: goga.Mutate},
+		},
+	)
+	genAlgo.Selector = goga.NewSelector(
+		[]goga.SelectorFunctionProbability{
+			{P: 1.0, F: goga.Roulette},
+		},
+	)
+	configure(&genAlgo)
+	genAlgo.Init(200, 8)
+
+	b.ResetTimer()
+	genAlgo.Terminator = goga.MaxGenerations(b.N)
+	genAlgo.Simulate()
+}
+
+func BenchmarkGenerational(b *testing.B) {
+	helperBenchmarkGeneticAlgorithm(b, func(*goga.GeneticAlgorithm) {})
+}
+
+func BenchmarkGenerationalParallelBreeding(b *testing.B) {
+	helperBenchmarkGeneticAlgorithm(b, func(ga *goga.GeneticAlgorithm) {
+		ga.ParallelBreeding = true
+	})
+}
+
+func BenchmarkSteadyState(b *testing.B) {
+	helperBenchmarkGeneticAlgorithm(b, func(ga *goga.GeneticAlgorithm) {
+		ga.SteadyState = true
+	})
+}
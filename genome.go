@@ -10,6 +10,8 @@ type Genome interface {
 type genome struct {
 	fitness int
 	bitset  Bitset
+	lineage *Lineage
+	Attributes
 }
 
 // NewGenome creates a genome with a bitset and
@@ -29,3 +31,73 @@ func (g *genome) SetFitness(fitness int) {
 func (g *genome) GetBits() *Bitset {
 	return &g.bitset
 }
+
+func (g *genome) GetLineage() Lineage {
+	if g.lineage == nil {
+		return Lineage{}
+	}
+	return *g.lineage
+}
+
+func (g *genome) SetLineage(lineage Lineage) {
+	g.lineage = &lineage
+}
+
+// GenomeCopier - an optional interface for genomes that hold more than a bitset,
+// for example expression trees, so that the default mater and the HallOfFame
+// can copy them and tell them apart
+// * Copy - returns a deep copy of the genome with a zero'd fitness score
+// * Key - returns a string that is equal for genomes with equal content
+type GenomeCopier interface {
+	Copy() Genome
+	Key() string
+}
+
+// GenomeCreate - an interface to an object that creates the genomes of the initial
+// population, used by the GeneticAlgorithm instead of its BitsetCreate when set
+type GenomeCreate interface {
+	Go() Genome
+}
+
+// newGenomeFrom returns a genome with the content of 'g' and a zero'd fitness score
+func newGenomeFrom(g Genome) Genome {
+	if copier, ok := g.(GenomeCopier); ok {
+		return copier.Copy()
+	}
+	return NewGenome(*g.GetBits())
+}
+
+// CopyGenome returns a deep copy of 'g', including its fitness score, lineage and attributes
+func CopyGenome(g Genome) Genome {
+	ret := copyContent(g)
+	ret.SetFitness(g.GetFitness())
+	copyLineage(g, ret)
+	copyAttributes(g, ret)
+	return ret
+}
+
+// copyContent returns a genome with a deep copy of the content of 'g' and a zero'd fitness score,
+// unlike newGenomeFrom the copy never shares its bits with 'g'
+func copyContent(g Genome) Genome {
+	if copier, ok := g.(GenomeCopier); ok {
+		return copier.Copy()
+	}
+	return NewGenome(g.GetBits().CreateCopy())
+}
+
+// copyLineage sets the lineage of 'to' to that of 'from', if both carry their lineage
+func copyLineage(from, to Genome) {
+	fromLineage, fromOk := from.(LineageGenome)
+	toLineage, toOk := to.(LineageGenome)
+	if fromOk && toOk {
+		toLineage.SetLineage(fromLineage.GetLineage())
+	}
+}
+
+// genomeKey returns a string that is equal for genomes with equal content
+func genomeKey(g Genome) string {
+	if copier, ok := g.(GenomeCopier); ok {
+		return copier.Key()
+	}
+	return g.GetBits().String()
+}
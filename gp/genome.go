@@ -0,0 +1,104 @@
+package gp
+
+import (
+	"sync"
+
+	"github.com/tomcraven/goga"
+)
+
+// TreeGenome - a goga.Genome that holds an expression tree rather than a bitset.
+// Simulators evaluate it by type asserting the genome, i.e.
+//
+//	tree := g.(*gp.TreeGenome)
+//	value := tree.Eval(inputs)
+//
+// Its bitset is always empty
+type TreeGenome struct {
+	Root    *Node
+	fitness int
+	bits    goga.Bitset
+	lineage goga.Lineage
+	goga.Attributes
+}
+
+// NewTreeGenome creates a genome with the tree 'root' and a zero'd fitness score
+func NewTreeGenome(root *Node) *TreeGenome {
+	return &TreeGenome{Root: root}
+}
+
+// GetFitness returns the genome's fitness score
+func (tg *TreeGenome) GetFitness() int {
+	return tg.fitness
+}
+
+// SetFitness sets the genome's fitness score
+func (tg *TreeGenome) SetFitness(fitness int) {
+	tg.fitness = fitness
+}
+
+// GetBits returns an empty bitset
+func (tg *TreeGenome) GetBits() *goga.Bitset {
+	return &tg.bits
+}
+
+// GetLineage returns where the genome came from
+func (tg *TreeGenome) GetLineage() goga.Lineage {
+	return tg.lineage
+}
+
+// SetLineage sets where the genome came from
+func (tg *TreeGenome) SetLineage(lineage goga.Lineage) {
+	tg.lineage = lineage
+}
+
+// Copy returns a genome with a deep copy of the tree and a zero'd fitness score
+func (tg *TreeGenome) Copy() goga.Genome {
+	return NewTreeGenome(tg.Root.Copy())
+}
+
+// Key returns the tree as an s-expression
+func (tg *TreeGenome) Key() string {
+	return tg.Root.String()
+}
+
+// Eval returns the value of the tree for 'inputs'
+func (tg *TreeGenome) Eval(inputs []float64) float64 {
+	return tg.Root.Eval(inputs)
+}
+
+type rampedHalfAndHalf struct {
+	set      *PrimitiveSet
+	minDepth int
+	maxDepth int
+	next     int
+	mutex    sync.Mutex
+}
+
+// NewRampedHalfAndHalf returns a goga.GenomeCreate that creates TreeGenomes from 'set'
+// with ramped half and half initialisation. Depths from 'minDepth' to 'maxDepth' are
+// used in turn, and at each depth alternate trees are created with Full and Grow,
+// giving an initial population with a wide range of shapes and sizes
+func NewRampedHalfAndHalf(set *PrimitiveSet, minDepth, maxDepth int) goga.GenomeCreate {
+	if maxDepth < minDepth {
+		maxDepth = minDepth
+	}
+	return &rampedHalfAndHalf{
+		set:      set,
+		minDepth: minDepth,
+		maxDepth: maxDepth,
+	}
+}
+
+// Go returns a new random TreeGenome
+func (rhh *rampedHalfAndHalf) Go() goga.Genome {
+	rhh.mutex.Lock()
+	i := rhh.next
+	rhh.next++
+	rhh.mutex.Unlock()
+
+	depth := rhh.minDepth + (i/2)%(rhh.maxDepth-rhh.minDepth+1)
+	if i%2 == 0 {
+		return NewTreeGenome(rhh.set.Full(depth))
+	}
+	return NewTreeGenome(rhh.set.Grow(depth))
+}
@@ -0,0 +1,158 @@
+package gp
+
+import (
+	"math/rand"
+
+	"github.com/tomcraven/goga"
+)
+
+const defaultMutationDepth = 2
+
+// Operators -
+// Creates the tree variation operators, which are used as the 'F' of a
+// goga.MaterFunctionProbability in the same way as goga.OnePointCrossover
+// * Set - the functions and terminals used by mutations
+// * MaxDepth - the deepest an offspring can be, 0 for no limit
+// * MaxSize - the most nodes an offspring can have, 0 for no limit
+// * MutationDepth - the deepest subtree SubtreeMutation creates, 2 if not set
+//
+// An offspring that breaks a limit is replaced by a copy of its parent
+type Operators struct {
+	Set           *PrimitiveSet
+	MaxDepth      int
+	MaxSize       int
+	MutationDepth int
+}
+
+func toTree(g goga.Genome) *TreeGenome {
+	tree, ok := g.(*TreeGenome)
+	if !ok {
+		panic("gp: genome is not a *TreeGenome")
+	}
+	return tree
+}
+
+// withinLimits returns 'offspring' if it is within the depth and size limits,
+// otherwise a copy of 'parent'
+func (o *Operators) withinLimits(offspring *Node, parent *TreeGenome) goga.Genome {
+	if (o.MaxDepth > 0 && offspring.Depth() > o.MaxDepth) ||
+		(o.MaxSize > 0 && offspring.Size() > o.MaxSize) {
+		return parent.Copy()
+	}
+	return NewTreeGenome(offspring)
+}
+
+// SubtreeCrossover returns a mater function that swaps a random subtree
+// of the first genome with a random subtree of the second
+// i.e.
+// input genomes of:
+// (add x 1) and (mul x (sub x 2))
+// could produce output genomes of:
+// (add x (sub x 2)) and (mul x 1)
+func (o Operators) SubtreeCrossover() func(goga.Genome, goga.Genome) (goga.Genome, goga.Genome) {
+	return func(g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+		t1, t2 := toTree(g1), toTree(g2)
+		root1, root2 := t1.Root.Copy(), t2.Root.Copy()
+
+		n1, n2 := root1.randomNode(), root2.randomNode()
+		*n1, *n2 = *n2, *n1
+
+		return o.withinLimits(root1, t1), o.withinLimits(root2, t2)
+	}
+}
+
+// PointMutation returns a mater function that replaces a random node of the first
+// genome with a random function of the same arity, or a random terminal
+// i.e.
+// input genomes of:
+// (add x 1) and (mul x 2)
+// could produce output genomes of:
+// (sub x 1) and (mul x 2)
+func (o Operators) PointMutation() func(goga.Genome, goga.Genome) (goga.Genome, goga.Genome) {
+	return func(g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+		t1 := toTree(g1)
+		root := t1.Root.Copy()
+
+		n := root.randomNode()
+		if n.Function == nil {
+			n.Terminal = o.Set.randomTerminal().Terminal
+		} else {
+			var candidates []*Function
+			for i := range o.Set.Functions {
+				if o.Set.Functions[i].Arity == n.Function.Arity {
+					candidates = append(candidates, &o.Set.Functions[i])
+				}
+			}
+			if len(candidates) > 0 {
+				n.Function = candidates[rand.Intn(len(candidates))]
+			}
+		}
+
+		return NewTreeGenome(root), toTree(g2).Copy()
+	}
+}
+
+// SubtreeMutation returns a mater function that replaces a random subtree of
+// the first genome with a new random tree, created with Grow
+// i.e.
+// input genomes of:
+// (add x 1) and (mul x 2)
+// could produce output genomes of:
+// (add x (mul x x)) and (mul x 2)
+func (o Operators) SubtreeMutation() func(goga.Genome, goga.Genome) (goga.Genome, goga.Genome) {
+	mutationDepth := o.MutationDepth
+	if mutationDepth <= 0 {
+		mutationDepth = defaultMutationDepth
+	}
+
+	return func(g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+		t1 := toTree(g1)
+		root := t1.Root.Copy()
+
+		n := root.randomNode()
+		*n = *o.Set.Grow(mutationDepth)
+
+		return o.withinLimits(root, t1), toTree(g2).Copy()
+	}
+}
+
+// HoistMutation returns a mater function that replaces the first genome with a
+// random subtree of itself, which can only make it smaller
+// i.e.
+// input genomes of:
+// (add x (mul x 2)) and (mul x 2)
+// could produce output genomes of:
+// (mul x 2) and (mul x 2)
+func (o Operators) HoistMutation() func(goga.Genome, goga.Genome) (goga.Genome, goga.Genome) {
+	return func(g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+		t1 := toTree(g1)
+		return NewTreeGenome(t1.Root.randomNode().Copy()), toTree(g2).Copy()
+	}
+}
+
+// genomeSize returns the number of nodes in a TreeGenome,
+// or the number of bits in any other genome
+func genomeSize(g goga.Genome) int {
+	if tree, ok := g.(*TreeGenome); ok {
+		return tree.Root.Size()
+	}
+	return g.GetBits().GetSize()
+}
+
+// LexicographicTournament returns a selector function, for use as the 'F' of a
+// goga.SelectorFunctionProbability, that picks 'size' random genomes and returns
+// the fittest of them. Genomes of equal fitness are ranked by size, smallest first,
+// so that trees do not bloat with code that does not improve their fitness
+func LexicographicTournament(size int) func([]goga.Genome, int) goga.Genome {
+	return func(population []goga.Genome, totalFitness int) goga.Genome {
+		best := population[rand.Intn(len(population))]
+		for i := 1; i < size; i++ {
+			contender := population[rand.Intn(len(population))]
+			if contender.GetFitness() > best.GetFitness() ||
+				(contender.GetFitness() == best.GetFitness() && genomeSize(contender) < genomeSize(best)) {
+				best = contender
+			}
+		}
+		return best
+	}
+}
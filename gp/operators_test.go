@@ -0,0 +1,170 @@
+package gp_test
+
+import (
+	"math"
+
+	"github.com/tomcraven/goga"
+	"github.com/tomcraven/goga/gp"
+	. "gopkg.in/check.v1"
+)
+
+type OperatorsSuite struct {
+	set       *gp.PrimitiveSet
+	operators gp.Operators
+}
+
+var _ = Suite(&OperatorsSuite{})
+
+func (s *OperatorsSuite) SetUpTest(t *C) {
+	s.set = &gp.PrimitiveSet{
+		Functions: []gp.Function{gp.Add, gp.Subtract, gp.Multiply},
+		Terminals: []gp.Terminal{gp.Variable("x", 0), gp.Constant(1)},
+	}
+	s.operators = gp.Operators{Set: s.set}
+}
+
+func (s *OperatorsSuite) helperTreeGenome(depth int) goga.Genome {
+	return gp.NewTreeGenome(s.set.Full(depth))
+}
+
+func (s *OperatorsSuite) TestShouldCrossoverSubtrees(t *C) {
+	crossover := s.operators.SubtreeCrossover()
+
+	for i := 0; i < 100; i++ {
+		g1, g2 := s.helperTreeGenome(2), s.helperTreeGenome(3)
+		key1, key2 := g1.(*gp.TreeGenome).Key(), g2.(*gp.TreeGenome).Key()
+
+		c1, c2 := crossover(g1, g2)
+
+		// Nodes are swapped between the offspring, and the parents are untouched
+		t.Assert(c1.(*gp.TreeGenome).Root.Size()+c2.(*gp.TreeGenome).Root.Size(), Equals, 7+15)
+		t.Assert(g1.(*gp.TreeGenome).Key(), Equals, key1)
+		t.Assert(g2.(*gp.TreeGenome).Key(), Equals, key2)
+	}
+}
+
+func (s *OperatorsSuite) TestShouldKeepOffspringWithinLimits(t *C) {
+	s.operators.MaxDepth = 3
+	s.operators.MaxSize = 11
+	crossover := s.operators.SubtreeCrossover()
+	mutation := s.operators.SubtreeMutation()
+
+	for i := 0; i < 200; i++ {
+		c1, c2 := crossover(s.helperTreeGenome(2), s.helperTreeGenome(3))
+		c1, _ = mutation(c1, c2)
+		for _, c := range []goga.Genome{c1, c2} {
+			root := c.(*gp.TreeGenome).Root
+			if root.Size() == 15 {
+				// An unchanged copy of the larger parent
+				continue
+			}
+			t.Assert(root.Depth() <= 3, Equals, true)
+			t.Assert(root.Size() <= 11, Equals, true)
+		}
+	}
+}
+
+func (s *OperatorsSuite) TestShouldMutatePoints(t *C) {
+	mutation := s.operators.PointMutation()
+
+	for i := 0; i < 100; i++ {
+		g1, g2 := s.helperTreeGenome(2), s.helperTreeGenome(1)
+		c1, c2 := mutation(g1, g2)
+
+		// The shape of the tree does not change
+		t.Assert(c1.(*gp.TreeGenome).Root.Size(), Equals, 7)
+		t.Assert(c1.(*gp.TreeGenome).Root.Depth(), Equals, 2)
+		t.Assert(c2.(*gp.TreeGenome).Key(), Equals, g2.(*gp.TreeGenome).Key())
+	}
+}
+
+func (s *OperatorsSuite) TestShouldHoistSubtrees(t *C) {
+	mutation := s.operators.HoistMutation()
+
+	for i := 0; i < 100; i++ {
+		g1 := s.helperTreeGenome(3)
+		c1, _ := mutation(g1, s.helperTreeGenome(1))
+
+		root := c1.(*gp.TreeGenome).Root
+		t.Assert(root.Size() <= 15, Equals, true)
+		t.Assert(root.Size(), Equals, 1<<uint(root.Depth()+1)-1)
+	}
+}
+
+func (s *OperatorsSuite) TestShouldWorkWithTheDefaultMater(t *C) {
+	mater := goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 1, F: s.operators.SubtreeCrossover()},
+		{P: 1, F: s.operators.PointMutation()},
+	})
+
+	g1, g2 := s.helperTreeGenome(2), s.helperTreeGenome(2)
+	c1, c2 := mater.Go(g1, g2)
+	_, ok1 := c1.(*gp.TreeGenome)
+	_, ok2 := c2.(*gp.TreeGenome)
+	t.Assert(ok1 && ok2, Equals, true)
+}
+
+func (s *OperatorsSuite) TestShouldPreferSmallerTreesOfEqualFitness(t *C) {
+	small, large := s.helperTreeGenome(1), s.helperTreeGenome(3)
+	small.SetFitness(5)
+	large.SetFitness(5)
+
+	selector := gp.LexicographicTournament(64)
+	for i := 0; i < 20; i++ {
+		selected := selector([]goga.Genome{large, small}, 10)
+		t.Assert(selected, Equals, small)
+	}
+
+	large.SetFitness(6)
+	for i := 0; i < 20; i++ {
+		selected := selector([]goga.Genome{large, small}, 11)
+		t.Assert(selected, Equals, large)
+	}
+}
+
+type symbolicRegressionSimulator struct {
+	goga.NullSimulator
+}
+
+// Simulate scores how closely the tree matches x^2 + x
+func (srs *symbolicRegressionSimulator) Simulate(g goga.Genome) {
+	tree := g.(*gp.TreeGenome)
+
+	totalError := 0.0
+	for x := -5.0; x <= 5; x++ {
+		totalError += math.Abs(tree.Eval([]float64{x}) - (x*x + x))
+	}
+	g.SetFitness(int(1000 / (1 + totalError)))
+}
+
+func (s *OperatorsSuite) TestShouldEvolveTrees(t *C) {
+	s.operators.MaxDepth = 6
+
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &symbolicRegressionSimulator{}
+	genAlgo.GenomeCreate = gp.NewRampedHalfAndHalf(s.set, 1, 3)
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 0.9, F: s.operators.SubtreeCrossover()},
+		{P: 0.1, F: s.operators.SubtreeMutation()},
+		{P: 0.1, F: s.operators.PointMutation()},
+		{P: 0.05, F: s.operators.HoistMutation()},
+	})
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: gp.LexicographicTournament(3)},
+	})
+	hallOfFame := goga.NewHallOfFame(5)
+	genAlgo.HallOfFame = hallOfFame
+	genAlgo.Terminator = goga.Any(goga.MaxGenerations(30), goga.TargetFitness(1000))
+	genAlgo.Init(100, 2)
+	genAlgo.Simulate()
+
+	for _, g := range genAlgo.GetPopulation() {
+		t.Assert(g.(*gp.TreeGenome).Root.Depth() <= 6, Equals, true)
+	}
+
+	// Trees are told apart by their expression rather than their empty bitset
+	best := hallOfFame.GetGenomes()
+	t.Assert(len(best), Equals, 5)
+	t.Assert(best[0].(*gp.TreeGenome).Key(), Not(Equals), best[1].(*gp.TreeGenome).Key())
+	t.Assert(best[0].GetFitness() > 0, Equals, true)
+}
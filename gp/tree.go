@@ -0,0 +1,203 @@
+package gp
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Function - an inner node of a tree with 'Arity' children,
+// 'F' is called with the values of its children
+type Function struct {
+	Name  string
+	Arity int
+	F     func(args []float64) float64
+}
+
+// Terminal - a leaf of a tree, 'Value' is called with the
+// inputs the tree is being evaluated with
+type Terminal struct {
+	Name  string
+	Value func(inputs []float64) float64
+}
+
+// Variable returns a Terminal whose value is input number 'index'
+func Variable(name string, index int) Terminal {
+	return Terminal{
+		Name: name,
+		Value: func(inputs []float64) float64 {
+			return inputs[index]
+		},
+	}
+}
+
+// Constant returns a Terminal that always has the value 'value'
+func Constant(value float64) Terminal {
+	return Terminal{
+		Name: strconv.FormatFloat(value, 'g', -1, 64),
+		Value: func([]float64) float64 {
+			return value
+		},
+	}
+}
+
+var (
+	// Add returns the sum of its two children
+	Add = Function{Name: "add", Arity: 2, F: func(args []float64) float64 {
+		return args[0] + args[1]
+	}}
+
+	// Subtract returns its first child minus its second
+	Subtract = Function{Name: "sub", Arity: 2, F: func(args []float64) float64 {
+		return args[0] - args[1]
+	}}
+
+	// Multiply returns the product of its two children
+	Multiply = Function{Name: "mul", Arity: 2, F: func(args []float64) float64 {
+		return args[0] * args[1]
+	}}
+
+	// ProtectedDivide returns its first child divided by its second,
+	// or 1 if the second is very close to 0
+	ProtectedDivide = Function{Name: "div", Arity: 2, F: func(args []float64) float64 {
+		if math.Abs(args[1]) < 1e-9 {
+			return 1
+		}
+		return args[0] / args[1]
+	}}
+)
+
+// PrimitiveSet - the functions and terminals that trees are built from
+type PrimitiveSet struct {
+	Functions []Function
+	Terminals []Terminal
+}
+
+// Node - a node of an expression tree, either a Function with
+// a child per argument or a Terminal with no children
+type Node struct {
+	Function *Function
+	Terminal *Terminal
+	Children []*Node
+}
+
+// Eval returns the value of the tree for 'inputs'
+func (n *Node) Eval(inputs []float64) float64 {
+	if n.Function == nil {
+		return n.Terminal.Value(inputs)
+	}
+
+	args := make([]float64, len(n.Children))
+	for i, child := range n.Children {
+		args[i] = child.Eval(inputs)
+	}
+	return n.Function.F(args)
+}
+
+// Size returns the number of nodes in the tree
+func (n *Node) Size() int {
+	size := 1
+	for _, child := range n.Children {
+		size += child.Size()
+	}
+	return size
+}
+
+// Depth returns the number of edges on the longest path from
+// the node to a leaf, a single terminal has a depth of 0
+func (n *Node) Depth() int {
+	depth := 0
+	for _, child := range n.Children {
+		if d := child.Depth() + 1; d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
+// Copy returns a deep copy of the tree
+func (n *Node) Copy() *Node {
+	ret := &Node{Function: n.Function, Terminal: n.Terminal}
+	if len(n.Children) > 0 {
+		ret.Children = make([]*Node, len(n.Children))
+		for i, child := range n.Children {
+			ret.Children[i] = child.Copy()
+		}
+	}
+	return ret
+}
+
+// String returns the tree as an s-expression, i.e. (add x (mul x 2))
+func (n *Node) String() string {
+	builder := strings.Builder{}
+	n.write(&builder)
+	return builder.String()
+}
+
+func (n *Node) write(builder *strings.Builder) {
+	if n.Function == nil {
+		builder.WriteString(n.Terminal.Name)
+		return
+	}
+
+	builder.WriteString("(")
+	builder.WriteString(n.Function.Name)
+	for _, child := range n.Children {
+		builder.WriteString(" ")
+		child.write(builder)
+	}
+	builder.WriteString(")")
+}
+
+// nodes returns every node of the tree, parents before their children
+func (n *Node) nodes() []*Node {
+	ret := []*Node{n}
+	for _, child := range n.Children {
+		ret = append(ret, child.nodes()...)
+	}
+	return ret
+}
+
+// randomNode returns a random node of the tree
+func (n *Node) randomNode() *Node {
+	nodes := n.nodes()
+	return nodes[rand.Intn(len(nodes))]
+}
+
+func (ps *PrimitiveSet) randomTerminal() *Node {
+	return &Node{Terminal: &ps.Terminals[rand.Intn(len(ps.Terminals))]}
+}
+
+func (ps *PrimitiveSet) randomFunction() *Node {
+	f := &ps.Functions[rand.Intn(len(ps.Functions))]
+	return &Node{Function: f, Children: make([]*Node, f.Arity)}
+}
+
+// Full returns a random tree where every terminal is at depth 'depth'
+func (ps *PrimitiveSet) Full(depth int) *Node {
+	if depth <= 0 || len(ps.Functions) == 0 {
+		return ps.randomTerminal()
+	}
+
+	n := ps.randomFunction()
+	for i := range n.Children {
+		n.Children[i] = ps.Full(depth - 1)
+	}
+	return n
+}
+
+// Grow returns a random tree no deeper than 'maxDepth', each node is
+// chosen from all of the functions and terminals until 'maxDepth' is reached
+func (ps *PrimitiveSet) Grow(maxDepth int) *Node {
+	numPrimitives := len(ps.Functions) + len(ps.Terminals)
+	if maxDepth <= 0 || rand.Intn(numPrimitives) < len(ps.Terminals) {
+		return ps.randomTerminal()
+	}
+
+	n := ps.randomFunction()
+	for i := range n.Children {
+		n.Children[i] = ps.Grow(maxDepth - 1)
+	}
+	return n
+}
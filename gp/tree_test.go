@@ -0,0 +1,105 @@
+package gp_test
+
+import (
+	"github.com/tomcraven/goga"
+	"github.com/tomcraven/goga/gp"
+	. "gopkg.in/check.v1"
+)
+
+type TreeSuite struct {
+	set *gp.PrimitiveSet
+}
+
+var _ = Suite(&TreeSuite{})
+
+func (s *TreeSuite) SetUpTest(t *C) {
+	s.set = &gp.PrimitiveSet{
+		Functions: []gp.Function{gp.Add, gp.Subtract, gp.Multiply, gp.ProtectedDivide},
+		Terminals: []gp.Terminal{gp.Variable("x", 0), gp.Constant(2)},
+	}
+}
+
+// helperTree builds (add x (mul x 2))
+func (s *TreeSuite) helperTree() *gp.Node {
+	x := &gp.Node{Terminal: &s.set.Terminals[0]}
+	two := &gp.Node{Terminal: &s.set.Terminals[1]}
+	mul := &gp.Node{Function: &s.set.Functions[2], Children: []*gp.Node{x, two}}
+	return &gp.Node{Function: &s.set.Functions[0], Children: []*gp.Node{x.Copy(), mul}}
+}
+
+func (s *TreeSuite) TestShouldEvaluateTrees(t *C) {
+	tree := s.helperTree()
+	t.Assert(tree.Eval([]float64{3}), Equals, 9.0)
+	t.Assert(tree.String(), Equals, "(add x (mul x 2))")
+	t.Assert(tree.Size(), Equals, 5)
+	t.Assert(tree.Depth(), Equals, 2)
+}
+
+func (s *TreeSuite) TestShouldProtectDivision(t *C) {
+	t.Assert(gp.ProtectedDivide.F([]float64{1, 0}), Equals, 1.0)
+	t.Assert(gp.ProtectedDivide.F([]float64{1, 4}), Equals, 0.25)
+}
+
+func (s *TreeSuite) TestShouldCopyTrees(t *C) {
+	tree := s.helperTree()
+	c := tree.Copy()
+	c.Children[1].Function = &s.set.Functions[0]
+
+	t.Assert(tree.String(), Equals, "(add x (mul x 2))")
+	t.Assert(c.String(), Equals, "(add x (add x 2))")
+}
+
+func (s *TreeSuite) TestShouldCreateFullTrees(t *C) {
+	for depth := 0; depth < 5; depth++ {
+		tree := s.set.Full(depth)
+		t.Assert(tree.Depth(), Equals, depth)
+		t.Assert(tree.Size(), Equals, 1<<uint(depth+1)-1)
+	}
+}
+
+func (s *TreeSuite) TestShouldCreateGrowTrees(t *C) {
+	depths := map[int]bool{}
+	for i := 0; i < 200; i++ {
+		tree := s.set.Grow(3)
+		t.Assert(tree.Depth() <= 3, Equals, true)
+		depths[tree.Depth()] = true
+	}
+	t.Assert(depths, DeepEquals, map[int]bool{0: true, 1: true, 2: true, 3: true})
+}
+
+func (s *TreeSuite) TestShouldCreateRampedHalfAndHalf(t *C) {
+	create := gp.NewRampedHalfAndHalf(s.set, 1, 3)
+
+	depths := map[int]int{}
+	for i := 0; i < 60; i++ {
+		g := create.Go()
+		tree, ok := g.(*gp.TreeGenome)
+		t.Assert(ok, Equals, true)
+		t.Assert(tree.GetFitness(), Equals, 0)
+		t.Assert(tree.GetBits().GetSize(), Equals, 0)
+
+		depth := tree.Root.Depth()
+		t.Assert(depth >= 0 && depth <= 3, Equals, true)
+		if i%2 == 0 {
+			// Full trees are always the ramped depth
+			t.Assert(depth, Equals, 1+(i/2)%3)
+		}
+		depths[depth]++
+	}
+	t.Assert(depths[1] >= 10 && depths[2] >= 10 && depths[3] >= 10, Equals, true)
+}
+
+func (s *TreeSuite) TestShouldCopyTreeGenomes(t *C) {
+	tree := gp.NewTreeGenome(s.helperTree())
+	tree.SetFitness(10)
+
+	var copier goga.GenomeCopier = tree
+	c := copier.Copy().(*gp.TreeGenome)
+	t.Assert(c.GetFitness(), Equals, 0)
+	t.Assert(c.Key(), Equals, tree.Key())
+	t.Assert(c.Root, Not(Equals), tree.Root)
+
+	c2 := goga.CopyGenome(tree)
+	t.Assert(c2.GetFitness(), Equals, 10)
+	t.Assert(c2.(*gp.TreeGenome).Eval([]float64{1}), Equals, 3.0)
+}
@@ -0,0 +1,215 @@
+package goga
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// HallOfFame - an interface to an object that remembers the best genomes
+// seen over every generation of a run
+type HallOfFame interface {
+	Update([]Genome)
+	GetGenomes() []Genome
+	Inject([]Genome) int
+}
+
+// NullHallOfFame - a null implementation of the HallOfFame interface
+type NullHallOfFame struct {
+}
+
+// Update - null implementation of HallOfFame's 'Update'
+func (nhof *NullHallOfFame) Update([]Genome) {
+}
+
+// GetGenomes - null implementation of HallOfFame's 'GetGenomes'
+func (nhof *NullHallOfFame) GetGenomes() []Genome {
+	return nil
+}
+
+// Inject - null implementation of HallOfFame's 'Inject'
+func (nhof *NullHallOfFame) Inject([]Genome) int {
+	return 0
+}
+
+type hallOfFame struct {
+	capacity int
+	genomes  []Genome
+	keys     map[string]Genome
+	mutex    sync.RWMutex
+}
+
+// NewHallOfFame returns a hall of fame that keeps the 'capacity' fittest
+// distinct genomes it has been given, genomes are distinct if their bitsets differ,
+// or their keys for genomes that implement GenomeCopier.
+// It is safe to query the hall of fame while the algorithm is running
+func NewHallOfFame(capacity int) HallOfFame {
+	return &hallOfFame{
+		capacity: capacity,
+		keys:     make(map[string]Genome),
+	}
+}
+
+// Update considers every genome in 'population' for a place in the hall of fame.
+// Copies are stored so later changes to the population do not affect it
+func (hof *hallOfFame) Update(population []Genome) {
+	hof.mutex.Lock()
+	defer hof.mutex.Unlock()
+
+	for _, g := range population {
+		if hof.capacity <= 0 {
+			break
+		}
+
+		fitness := g.GetFitness()
+		if len(hof.genomes) >= hof.capacity &&
+			fitness <= hof.genomes[len(hof.genomes)-1].GetFitness() {
+			continue
+		}
+
+		key := genomeKey(g)
+		if existing, ok := hof.keys[key]; ok {
+			if fitness > existing.GetFitness() {
+				existing.SetFitness(fitness)
+				hof.sortAndTrim()
+			}
+			continue
+		}
+
+		c := CopyGenome(g)
+		hof.keys[key] = c
+
+		// Keep the genomes ordered fittest first, after any of equal fitness
+		index := sort.Search(len(hof.genomes), func(i int) bool {
+			return hof.genomes[i].GetFitness() < fitness
+		})
+		hof.genomes = append(hof.genomes, nil)
+		copy(hof.genomes[index+1:], hof.genomes[index:])
+		hof.genomes[index] = c
+
+		hof.trim()
+	}
+}
+
+func (hof *hallOfFame) sortAndTrim() {
+	sort.SliceStable(hof.genomes, func(i, j int) bool {
+		return hof.genomes[i].GetFitness() > hof.genomes[j].GetFitness()
+	})
+	hof.trim()
+}
+
+func (hof *hallOfFame) trim() {
+	for len(hof.genomes) > hof.capacity {
+		last := hof.genomes[len(hof.genomes)-1]
+		delete(hof.keys, genomeKey(last))
+		hof.genomes = hof.genomes[:len(hof.genomes)-1]
+	}
+}
+
+// GetGenomes returns copies of the genomes in the hall of fame, fittest first
+func (hof *hallOfFame) GetGenomes() []Genome {
+	hof.mutex.RLock()
+	defer hof.mutex.RUnlock()
+
+	ret := make([]Genome, len(hof.genomes))
+	for i, g := range hof.genomes {
+		ret[i] = CopyGenome(g)
+	}
+	return ret
+}
+
+// Inject replaces the least fit members of 'population' with copies of the
+// hall of fame genomes that are not already in it, returning the number replaced
+func (hof *hallOfFame) Inject(population []Genome) int {
+	hof.mutex.RLock()
+	defer hof.mutex.RUnlock()
+
+	inPopulation := make(map[string]bool, len(population))
+	for _, g := range population {
+		inPopulation[genomeKey(g)] = true
+	}
+
+	indices := make([]int, len(population))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return population[indices[i]].GetFitness() < population[indices[j]].GetFitness()
+	})
+
+	injected := 0
+	for _, g := range hof.genomes {
+		if injected >= len(population) {
+			break
+		}
+		if inPopulation[genomeKey(g)] {
+			continue
+		}
+		population[indices[injected]] = CopyGenome(g)
+		injected++
+	}
+	return injected
+}
+
+type hallOfFameJSONGenome struct {
+	Fitness int    `json:"fitness"`
+	Bits    string `json:"bits"`
+}
+
+type hallOfFameJSON struct {
+	Capacity int                    `json:"capacity"`
+	Genomes  []hallOfFameJSONGenome `json:"genomes"`
+}
+
+// MarshalJSON encodes the capacity and genomes of the hall of fame, each
+// genome's bitset is encoded as a string of 0s and 1s. Only genomes that are
+// their bitset can be encoded, it returns an error for those that implement GenomeCopier
+func (hof *hallOfFame) MarshalJSON() ([]byte, error) {
+	hof.mutex.RLock()
+	defer hof.mutex.RUnlock()
+
+	ret := hallOfFameJSON{
+		Capacity: hof.capacity,
+		Genomes:  make([]hallOfFameJSONGenome, len(hof.genomes)),
+	}
+	for i, g := range hof.genomes {
+		if _, ok := g.(GenomeCopier); ok {
+			return nil, fmt.Errorf("cannot encode %T, only its bitset would be kept", g)
+		}
+		ret.Genomes[i] = hallOfFameJSONGenome{
+			Fitness: g.GetFitness(),
+			Bits:    g.GetBits().String(),
+		}
+	}
+	return json.Marshal(ret)
+}
+
+// UnmarshalJSON replaces the contents of the hall of fame with those
+// previously encoded by MarshalJSON
+func (hof *hallOfFame) UnmarshalJSON(data []byte) error {
+	var decoded hallOfFameJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	genomes := make([]Genome, len(decoded.Genomes))
+	keys := make(map[string]Genome, len(decoded.Genomes))
+	for i, jsonGenome := range decoded.Genomes {
+		bits, err := ParseBitset(jsonGenome.Bits)
+		if err != nil {
+			return err
+		}
+		genomes[i] = NewGenome(bits)
+		genomes[i].SetFitness(jsonGenome.Fitness)
+		keys[genomeKey(genomes[i])] = genomes[i]
+	}
+
+	hof.mutex.Lock()
+	defer hof.mutex.Unlock()
+	hof.capacity = decoded.Capacity
+	hof.genomes = genomes
+	hof.keys = keys
+	hof.sortAndTrim()
+	return nil
+}
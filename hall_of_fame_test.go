@@ -0,0 +1,223 @@
+package goga_test
+
+import (
+	"encoding/json"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type HallOfFameSuite struct {
+	hof goga.HallOfFame
+}
+
+func (s *HallOfFameSuite) SetUpTest(t *C) {
+	s.hof = goga.NewHallOfFame(3)
+}
+func (s *HallOfFameSuite) TearDownTest(t *C) {
+	s.hof = nil
+}
+
+var _ = Suite(&HallOfFameSuite{})
+
+func helperGenomeFromString(t *C, bits string, fitness int) goga.Genome {
+	b, err := goga.ParseBitset(bits)
+	t.Assert(err, IsNil)
+	g := goga.NewGenome(b)
+	g.SetFitness(fitness)
+	return g
+}
+
+func helperGenomeStrings(genomes []goga.Genome) []string {
+	ret := make([]string, len(genomes))
+	for i, g := range genomes {
+		ret[i] = g.GetBits().String()
+	}
+	return ret
+}
+
+func (s *HallOfFameSuite) TestShouldKeepFittestGenomesFirst(t *C) {
+	s.hof.Update([]goga.Genome{
+		helperGenomeFromString(t, "0001", 1),
+		helperGenomeFromString(t, "0010", 5),
+		helperGenomeFromString(t, "0011", 3),
+		helperGenomeFromString(t, "0100", 4),
+	})
+	t.Assert(helperGenomeStrings(s.hof.GetGenomes()), DeepEquals, []string{"0010", "0100", "0011"})
+
+	// A later generation that is worse overall still contributes its best genome
+	s.hof.Update([]goga.Genome{
+		helperGenomeFromString(t, "1000", 0),
+		helperGenomeFromString(t, "1001", 6),
+	})
+	genomes := s.hof.GetGenomes()
+	t.Assert(helperGenomeStrings(genomes), DeepEquals, []string{"1001", "0010", "0100"})
+	t.Assert(genomes[0].GetFitness(), Equals, 6)
+}
+
+func (s *HallOfFameSuite) TestShouldNotKeepDuplicateBitsets(t *C) {
+	s.hof.Update([]goga.Genome{
+		helperGenomeFromString(t, "0001", 5),
+		helperGenomeFromString(t, "0001", 5),
+		helperGenomeFromString(t, "0010", 1),
+	})
+	s.hof.Update([]goga.Genome{
+		helperGenomeFromString(t, "0001", 7),
+	})
+
+	genomes := s.hof.GetGenomes()
+	t.Assert(helperGenomeStrings(genomes), DeepEquals, []string{"0001", "0010"})
+	t.Assert(genomes[0].GetFitness(), Equals, 7)
+}
+
+func (s *HallOfFameSuite) TestShouldStoreCopies(t *C) {
+	g := helperGenomeFromString(t, "0001", 5)
+	s.hof.Update([]goga.Genome{g})
+
+	g.GetBits().Set(0, 1)
+	g.SetFitness(100)
+
+	genomes := s.hof.GetGenomes()
+	t.Assert(helperGenomeStrings(genomes), DeepEquals, []string{"0001"})
+	t.Assert(genomes[0].GetFitness(), Equals, 5)
+}
+
+func (s *HallOfFameSuite) TestShouldInjectIntoLeastFitMembers(t *C) {
+	s.hof.Update([]goga.Genome{
+		helperGenomeFromString(t, "1111", 10),
+		helperGenomeFromString(t, "1110", 9),
+	})
+
+	population := []goga.Genome{
+		helperGenomeFromString(t, "0000", 3),
+		helperGenomeFromString(t, "1110", 1),
+		helperGenomeFromString(t, "0001", 2),
+	}
+	t.Assert(s.hof.Inject(population), Equals, 1)
+	t.Assert(helperGenomeStrings(population), DeepEquals, []string{"0000", "1111", "0001"})
+	t.Assert(population[1].GetFitness(), Equals, 10)
+}
+
+func (s *HallOfFameSuite) TestShouldSerialiseToJSON(t *C) {
+	s.hof.Update([]goga.Genome{
+		helperGenomeFromString(t, "0001", 1),
+		helperGenomeFromString(t, "0010", 2),
+	})
+
+	data, err := json.Marshal(s.hof)
+	t.Assert(err, IsNil)
+	t.Assert(string(data), Equals,
+		`{"capacity":3,"genomes":[{"fitness":2,"bits":"0010"},{"fitness":1,"bits":"0001"}]}`)
+
+	loaded := goga.NewHallOfFame(0)
+	t.Assert(json.Unmarshal(data, loaded), IsNil)
+	t.Assert(helperGenomeStrings(loaded.GetGenomes()), DeepEquals, []string{"0010", "0001"})
+
+	loaded.Update([]goga.Genome{helperGenomeFromString(t, "0100", 3)})
+	loaded.Update([]goga.Genome{helperGenomeFromString(t, "1000", 4)})
+	t.Assert(helperGenomeStrings(loaded.GetGenomes()), DeepEquals, []string{"1000", "0100", "0010"})
+}
+
+type MyNamedGenome struct {
+	goga.Genome
+	name string
+}
+
+func (mg *MyNamedGenome) Copy() goga.Genome {
+	return &MyNamedGenome{goga.NewGenome(mg.GetBits().CreateCopy()), mg.name}
+}
+func (mg *MyNamedGenome) Key() string {
+	return mg.name
+}
+
+func (s *HallOfFameSuite) TestShouldNotSerialiseGenomeCopiers(t *C) {
+	s.hof.Update([]goga.Genome{&MyNamedGenome{helperGenomeFromString(t, "0001", 1), "a"}})
+
+	_, err := json.Marshal(s.hof)
+	t.Assert(err, NotNil)
+}
+
+type MySimulatorFitnessSequence struct {
+	Fitnesses []int
+	index     int
+}
+
+func (ms *MySimulatorFitnessSequence) OnBeginSimulation() {
+}
+func (ms *MySimulatorFitnessSequence) Simulate(g goga.Genome) {
+	g.SetFitness(ms.Fitnesses[ms.index%len(ms.Fitnesses)])
+}
+func (ms *MySimulatorFitnessSequence) OnEndSimulation() {
+	ms.index++
+}
+func (ms *MySimulatorFitnessSequence) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+func (s *HallOfFameSuite) TestShouldTrackBestGenomesOverRun(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.HallOfFame = s.hof
+	genAlgo.Simulator = &MySimulatorFitnessSequence{Fitnesses: []int{5, 9, 2}}
+	genAlgo.BitsetCreate = &MyBitsetCreateSequence{Size: 4}
+	genAlgo.Init(4, kNumThreads)
+	genAlgo.SimulateUntil(helperGenerateExitFunction(3))
+
+	// Every genome after the first generation is a copy of "0000"
+	genomes := s.hof.GetGenomes()
+	t.Assert(genomes, HasLen, 3)
+	t.Assert(genomes[0].GetBits().String(), Equals, "0000")
+	t.Assert(genomes[0].GetFitness(), Equals, 9)
+	t.Assert(genomes[1].GetFitness(), Equals, 5)
+	t.Assert(genomes[2].GetFitness(), Equals, 5)
+}
+
+type MySelectorMixedFitness struct {
+	SawMixedPopulation bool
+}
+
+func (ms *MySelectorMixedFitness) Go(genomes []goga.Genome, totalFitness int) goga.Genome {
+	sawLow, sawHigh := false, false
+	for _, g := range genomes {
+		sawLow = sawLow || g.GetFitness() == 1
+		sawHigh = sawHigh || g.GetFitness() == 9
+	}
+	ms.SawMixedPopulation = ms.SawMixedPopulation || (sawLow && sawHigh)
+	return genomes[0]
+}
+
+func (s *HallOfFameSuite) TestShouldInjectOnStagnation(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.HallOfFame = s.hof
+	genAlgo.Simulator = &MySimulatorFitnessSequence{Fitnesses: []int{9, 1}}
+	genAlgo.BitsetCreate = &MyBitsetCreateSequence{Size: 4}
+
+	// Without injection each generation has a single fitness
+	selector := MySelectorMixedFitness{}
+	genAlgo.Selector = &selector
+	genAlgo.Init(4, kNumThreads)
+	genAlgo.SimulateUntil(helperGenerateExitFunction(3))
+	t.Assert(selector.SawMixedPopulation, IsFalse)
+
+	// The second generation has not improved on the first so the
+	// first's genomes are injected before breeding the third
+	genAlgo.HallOfFameStagnation = 1
+	genAlgo.Simulator = &MySimulatorFitnessSequence{Fitnesses: []int{9, 1}}
+	genAlgo.Init(4, kNumThreads)
+	genAlgo.SimulateUntil(helperGenerateExitFunction(3))
+	t.Assert(selector.SawMixedPopulation, IsTrue)
+}
+
+type MyBitsetCreateSequence struct {
+	Size  int
+	count int
+}
+
+func (bc *MyBitsetCreateSequence) Go() goga.Bitset {
+	b := goga.Bitset{}
+	b.Create(bc.Size)
+	for i := 0; i < bc.Size; i++ {
+		b.Set(i, (bc.count>>uint(i))&1)
+	}
+	bc.count++
+	return b
+}
@@ -12,3 +12,19 @@ type NullEliteConsumer struct {
 // OnElite - null implementation on OnElite from the EliteConsumer interface
 func (nec *NullEliteConsumer) OnElite(Genome) {
 }
+
+type multiEliteConsumer struct {
+	consumers []EliteConsumer
+}
+
+// NewMultiEliteConsumer returns an EliteConsumer that passes each elite to
+// every one of 'consumers' in turn, e.g. to log a run as well as display it
+func NewMultiEliteConsumer(consumers ...EliteConsumer) EliteConsumer {
+	return &multiEliteConsumer{consumers: consumers}
+}
+
+func (mec *multiEliteConsumer) OnElite(g Genome) {
+	for _, consumer := range mec.consumers {
+		consumer.OnElite(g)
+	}
+}
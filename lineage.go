@@ -0,0 +1,251 @@
+package goga
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Lineage - where a genome came from
+// * ID - unique within a LineageRecorder, 0 until the genome is given one
+// * ParentIDs - the IDs of the genomes it was bred from, empty for created genomes
+// * Operators - the names of the operators that bred it, only named when its parents had an ID
+// * BirthGeneration - the generation it was first part of
+// * Age - the number of generations it has survived
+// * Fitness - its fitness when it was last recorded
+type Lineage struct {
+	ID              uint64   `json:"id"`
+	ParentIDs       []uint64 `json:"parentIds,omitempty"`
+	Operators       []string `json:"operators,omitempty"`
+	BirthGeneration int      `json:"birthGeneration"`
+	Age             int      `json:"age"`
+	Fitness         int      `json:"fitness"`
+}
+
+// LineageGenome - an optional interface for genomes that carry their Lineage,
+// it is implemented by the genomes of this package and gp.TreeGenome. The lineage is kept
+// when the genome is copied with CopyGenome, but not by the offspring bred from it
+type LineageGenome interface {
+	GetLineage() Lineage
+	SetLineage(Lineage)
+}
+
+// LineageRecorder - an interface to an object that remembers the lineage of the
+// genomes of a run, so the ancestry of any of them can be looked up
+// * NewID - returns an ID that has not been returned before
+// * Record - stores, or updates, the lineage of a genome
+// * Get - returns the lineage with an ID, and false if there isn't one
+// * Ancestry - returns the lineage with an ID followed by that of all of its ancestors
+type LineageRecorder interface {
+	NewID() uint64
+	Record(Lineage)
+	Get(uint64) (Lineage, bool)
+	Ancestry(uint64) []Lineage
+}
+
+type lineageRecorder struct {
+	nextID   uint64
+	lineages map[uint64]Lineage
+	mutex    sync.Mutex
+}
+
+// NewLineageRecorder returns a LineageRecorder that keeps the lineage of every
+// genome that has been part of a population in memory. It is safe to query the
+// recorder while the algorithm is running
+func NewLineageRecorder() LineageRecorder {
+	return &lineageRecorder{
+		lineages: make(map[uint64]Lineage),
+	}
+}
+
+func (lr *lineageRecorder) NewID() uint64 {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+	lr.nextID++
+	return lr.nextID
+}
+
+func (lr *lineageRecorder) Record(lineage Lineage) {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+	lr.lineages[lineage.ID] = lineage
+}
+
+func (lr *lineageRecorder) Get(id uint64) (Lineage, bool) {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+	lineage, ok := lr.lineages[id]
+	return lineage, ok
+}
+
+// Ancestry returns the lineage of 'id' and its ancestors, nearest first.
+// Ancestors that were never recorded are left out
+func (lr *lineageRecorder) Ancestry(id uint64) []Lineage {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+
+	var ret []Lineage
+	visited := map[uint64]bool{id: true}
+	queue := []uint64{id}
+	for len(queue) > 0 {
+		lineage, ok := lr.lineages[queue[0]]
+		queue = queue[1:]
+		if !ok {
+			continue
+		}
+
+		ret = append(ret, lineage)
+		for _, parent := range lineage.ParentIDs {
+			if !visited[parent] {
+				visited[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return ret
+}
+
+// WriteLineageJSON writes the ancestry of 'id' from 'recorder' to 'w' as a JSON array
+func WriteLineageJSON(w io.Writer, recorder LineageRecorder, id uint64) error {
+	ancestry := recorder.Ancestry(id)
+	if ancestry == nil {
+		ancestry = []Lineage{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(ancestry)
+}
+
+// WriteLineageDOT writes the ancestry of 'id' from 'recorder' to 'w' as a Graphviz
+// DOT graph, with an edge from each parent to its offspring labelled with the
+// operators that bred it, e.g. for rendering with `dot -Tsvg`
+func WriteLineageDOT(w io.Writer, recorder LineageRecorder, id uint64) error {
+	var b strings.Builder
+	b.WriteString("digraph lineage {\n")
+	for _, lineage := range recorder.Ancestry(id) {
+		fmt.Fprintf(&b, "\t%v [label=\"%v\\nfitness %v\\ngeneration %v\"];\n",
+			lineage.ID, lineage.ID, lineage.Fitness, lineage.BirthGeneration)
+		for _, parent := range lineage.ParentIDs {
+			fmt.Fprintf(&b, "\t%v -> %v [label=%q];\n", parent, lineage.ID, strings.Join(lineage.Operators, ", "))
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// operatorName returns 'name', or if it is empty the name of the function 'f'
+// without its package or the suffix given to closures,
+// e.g. "OnePointCrossover" or "LengthConfig.BlockInsertion"
+func operatorName(name string, f interface{}) string {
+	if name != "" {
+		return name
+	}
+
+	fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fn == nil {
+		return ""
+	}
+
+	ret := fn.Name()
+	ret = ret[strings.LastIndex(ret, "/")+1:]
+	ret = ret[strings.Index(ret, ".")+1:]
+	for {
+		i := strings.LastIndex(ret, ".")
+		if i < 0 || !isClosureSuffix(ret[i+1:]) {
+			return ret
+		}
+		ret = ret[:i]
+	}
+}
+
+// isClosureSuffix returns true for the "func1" or "1" the compiler names closures with
+func isClosureSuffix(s string) bool {
+	s = strings.TrimPrefix(s, "func")
+	return s != "" && strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) }) < 0
+}
+
+// recordsLineage returns true if 'g' has been given an ID by a LineageRecorder,
+// the operators that breed offspring from it are only named when it has
+func recordsLineage(g Genome) bool {
+	lineageGenome, ok := g.(LineageGenome)
+	return ok && lineageGenome.GetLineage().ID != 0
+}
+
+// setOperators records the names of the operators that bred 'g', if it carries its lineage
+func setOperators(g Genome, operators []string) {
+	if len(operators) == 0 {
+		return
+	}
+	if lineageGenome, ok := g.(LineageGenome); ok {
+		lineage := lineageGenome.GetLineage()
+		lineage.Operators = operators
+		lineageGenome.SetLineage(lineage)
+	}
+}
+
+// inheritLineage gives each of 'offspring' a new ID from 'recorder', the IDs of its
+// 'parents' and the generation it will be born in
+func inheritLineage(recorder LineageRecorder, offspring, parents []Genome, generation int) {
+	var parentIDs []uint64
+	for _, p := range parents {
+		if lineageGenome, ok := p.(LineageGenome); ok {
+			id := lineageGenome.GetLineage().ID
+			if id != 0 && !containsID(parentIDs, id) {
+				parentIDs = append(parentIDs, id)
+			}
+		}
+	}
+
+	for _, g := range offspring {
+		if lineageGenome, ok := g.(LineageGenome); ok {
+			lineage := lineageGenome.GetLineage()
+			lineage.ID = recorder.NewID()
+			lineage.ParentIDs = parentIDs
+			lineage.BirthGeneration = generation
+			lineageGenome.SetLineage(lineage)
+		}
+	}
+}
+
+func containsID(ids []uint64, id uint64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// recordLineage passes the lineage of every genome in the population to the
+// LineageRecorder, genomes that were not bred, such as the initial population,
+// are given an ID and are born in the current generation
+func (ga *GeneticAlgorithm) recordLineage() {
+	if ga.LineageRecorder == nil {
+		return
+	}
+
+	generation := ga.runState.Generation
+	for _, g := range ga.population {
+		lineageGenome, ok := g.(LineageGenome)
+		if !ok {
+			continue
+		}
+
+		lineage := lineageGenome.GetLineage()
+		if lineage.ID == 0 {
+			lineage.ID = ga.LineageRecorder.NewID()
+			lineage.BirthGeneration = generation
+		}
+		lineage.Age = generation - lineage.BirthGeneration
+		lineage.Fitness = g.GetFitness()
+		lineageGenome.SetLineage(lineage)
+		ga.LineageRecorder.Record(lineage)
+	}
+}
@@ -0,0 +1,151 @@
+package goga_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type LineageSuite struct {
+}
+
+var _ = Suite(&LineageSuite{})
+
+func helperLineage(g goga.Genome) goga.Lineage {
+	return g.(goga.LineageGenome).GetLineage()
+}
+
+func (s *LineageSuite) TestShouldNameOperators(t *C) {
+	lengths := goga.LengthConfig{BlockSize: 2}
+	mater := goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.OnePointCrossover},
+		{P: 1, F: goga.Mutate, Name: "flip"},
+		{P: 1, F: lengths.BlockDuplication()},
+		{P: 0, F: goga.UniformCrossover},
+	})
+
+	// Operators are only named when the parents' lineage is being recorded
+	p1, p2 := helperGenome(t, "0000"), helperGenome(t, "1111")
+	c1, _ := mater.Go(p1, p2)
+	t.Assert(helperLineage(c1).Operators, IsNil)
+
+	p1.(goga.LineageGenome).SetLineage(goga.Lineage{ID: 1})
+	p2.(goga.LineageGenome).SetLineage(goga.Lineage{ID: 2})
+	c1, c2 := mater.Go(p1, p2)
+	operators := []string{"OnePointCrossover", "flip", "LengthConfig.BlockDuplication"}
+	t.Assert(helperLineage(c1).Operators, DeepEquals, operators)
+	t.Assert(helperLineage(c2).Operators, DeepEquals, operators)
+
+	reproducer := goga.NewReproducer(3, 1, goga.GenePoolRecombination)
+	parents := []goga.Genome{helperGenome(t, "0"), helperGenome(t, "1"), helperGenome(t, "1")}
+	offspring := reproducer.Go(parents)
+	t.Assert(helperLineage(offspring[0]).Operators, IsNil)
+
+	parents[0].(goga.LineageGenome).SetLineage(goga.Lineage{ID: 3})
+	offspring = reproducer.Go(parents)
+	t.Assert(helperLineage(offspring[0]).Operators, DeepEquals, []string{"GenePoolRecombination"})
+}
+
+func (s *LineageSuite) TestShouldCopyLineage(t *C) {
+	g := helperGenome(t, "0101")
+	g.(goga.LineageGenome).SetLineage(goga.Lineage{ID: 5, ParentIDs: []uint64{1, 2}})
+
+	t.Assert(helperLineage(goga.CopyGenome(g)), DeepEquals, helperLineage(g))
+	t.Assert(helperLineage(goga.NewSelfAdaptiveGenome(*g.GetBits(), 0.1)), DeepEquals, goga.Lineage{})
+}
+
+// helperRecorder records a small family, 4 is bred from 2 and 3, which were both bred from 1
+func helperRecorder() goga.LineageRecorder {
+	recorder := goga.NewLineageRecorder()
+	for i := 0; i < 5; i++ {
+		recorder.NewID()
+	}
+	recorder.Record(goga.Lineage{ID: 1, BirthGeneration: 1, Fitness: 1})
+	recorder.Record(goga.Lineage{ID: 2, ParentIDs: []uint64{1}, Operators: []string{"Mutate"}, BirthGeneration: 2, Fitness: 2})
+	recorder.Record(goga.Lineage{ID: 3, ParentIDs: []uint64{1}, Operators: []string{"Mutate"}, BirthGeneration: 2, Fitness: 3})
+	recorder.Record(goga.Lineage{ID: 4, ParentIDs: []uint64{2, 3, 9}, Operators: []string{"OnePointCrossover", "Mutate"}, BirthGeneration: 3, Fitness: 4})
+	return recorder
+}
+
+func (s *LineageSuite) TestShouldRecordAncestry(t *C) {
+	recorder := helperRecorder()
+	t.Assert(recorder.NewID(), Equals, uint64(6))
+
+	ids := []uint64{}
+	for _, lineage := range recorder.Ancestry(4) {
+		ids = append(ids, lineage.ID)
+	}
+	t.Assert(ids, DeepEquals, []uint64{4, 2, 3, 1})
+
+	_, ok := recorder.Get(9)
+	t.Assert(ok, IsFalse)
+	t.Assert(recorder.Ancestry(9), HasLen, 0)
+}
+
+func (s *LineageSuite) TestShouldWriteLineageJSON(t *C) {
+	var buffer bytes.Buffer
+	t.Assert(goga.WriteLineageJSON(&buffer, helperRecorder(), 2), IsNil)
+
+	var decoded []goga.Lineage
+	t.Assert(json.Unmarshal(buffer.Bytes(), &decoded), IsNil)
+	t.Assert(decoded, DeepEquals, []goga.Lineage{
+		{ID: 2, ParentIDs: []uint64{1}, Operators: []string{"Mutate"}, BirthGeneration: 2, Fitness: 2},
+		{ID: 1, BirthGeneration: 1, Fitness: 1},
+	})
+	t.Assert(bytes.Contains(buffer.Bytes(), []byte(`"parentIds"`)), IsTrue)
+}
+
+func (s *LineageSuite) TestShouldWriteLineageDOT(t *C) {
+	var buffer bytes.Buffer
+	t.Assert(goga.WriteLineageDOT(&buffer, helperRecorder(), 3), IsNil)
+	t.Assert(buffer.String(), Equals, `digraph lineage {
+	3 [label="3\nfitness 3\ngeneration 2"];
+	1 -> 3 [label="Mutate"];
+	1 [label="1\nfitness 1\ngeneration 1"];
+}
+`)
+}
+
+func (s *LineageSuite) TestShouldRecordTheLineageOfARun(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	recorder := goga.NewLineageRecorder()
+	genAlgo.LineageRecorder = recorder
+	genAlgo.Simulator = &MySimulatorCountOnes{}
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(16)
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.UniformCrossover},
+		{P: 1, F: goga.Mutate},
+	})
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: goga.Roulette},
+	})
+	genAlgo.KeepParents = true
+	genAlgo.Terminator = goga.MaxGenerations(10)
+	genAlgo.Init(10, 2)
+	genAlgo.Simulate()
+
+	elite := helperLineage(genAlgo.GetRunState().Elite)
+	t.Assert(elite.ID, Not(Equals), uint64(0))
+
+	births := map[uint64]int{}
+	for _, lineage := range recorder.Ancestry(elite.ID) {
+		births[lineage.ID] = lineage.BirthGeneration
+		t.Assert(lineage.Age >= 0, IsTrue)
+		if lineage.BirthGeneration == 1 {
+			// The initial population
+			t.Assert(lineage.ParentIDs, HasLen, 0)
+			continue
+		}
+		t.Assert(lineage.Operators, DeepEquals, []string{"UniformCrossover", "Mutate"})
+		t.Assert(len(lineage.ParentIDs) >= 1 && len(lineage.ParentIDs) <= 2, IsTrue)
+	}
+
+	// Every ancestor is older than its offspring
+	for _, lineage := range recorder.Ancestry(elite.ID) {
+		for _, parent := range lineage.ParentIDs {
+			t.Assert(births[parent] < lineage.BirthGeneration, IsTrue)
+		}
+	}
+}
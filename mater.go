@@ -16,7 +16,7 @@ type NullMater struct {
 
 // Go - null implementation of the IMater go func
 func (nm *NullMater) Go(a, b Genome) (Genome, Genome) {
-	return NewGenome(*a.GetBits()), NewGenome(*b.GetBits())
+	return newGenomeFrom(a), newGenomeFrom(b)
 }
 
 // OnElite - null implementation of the IMater OnElite func
@@ -28,14 +28,17 @@ func (nm *NullMater) OnElite(a Genome) {
 // where mater function 'F' is called with a probability of 'P'
 // where 'P' is a value between 0 and 1
 // 0 = never called, 1 = called for every genome
+// 'Name' is recorded in the Lineage of the offspring, the name of 'F' is used if it is empty
 type MaterFunctionProbability struct {
 	P        float32
 	F        func(Genome, Genome) (Genome, Genome)
 	UseElite bool
+	Name     string
 }
 
 type mater struct {
 	materConfig []MaterFunctionProbability
+	names       []string
 	elite       Genome
 }
 
@@ -43,25 +46,42 @@ type mater struct {
 func NewMater(materConfig []MaterFunctionProbability) Mater {
 	return &mater{
 		materConfig: materConfig,
+		names:       operatorNames(materConfig),
 	}
 }
 
+// operatorNames returns the name of each function of 'materConfig' for lineages
+func operatorNames(materConfig []MaterFunctionProbability) []string {
+	names := make([]string, len(materConfig))
+	for i, config := range materConfig {
+		names[i] = operatorName(config.Name, config.F)
+	}
+	return names
+}
+
 // Go cycles through, and applies, the configures mater functions in the
 // MaterFunctionProbability array
 func (m *mater) Go(g1, g2 Genome) (Genome, Genome) {
 
-	newG1 := NewGenome(*g1.GetBits())
-	newG2 := NewGenome(*g2.GetBits())
-	for _, config := range m.materConfig {
+	newG1 := newGenomeFrom(g1)
+	newG2 := newGenomeFrom(g2)
+	record := recordsLineage(g1)
+	var operators []string
+	for i, config := range m.materConfig {
 		if rand.Float32() < config.P {
 			if config.UseElite {
 				newG1, newG2 = config.F(newG1, m.elite)
 			} else {
 				newG1, newG2 = config.F(newG1, newG2)
 			}
+			if record {
+				operators = append(operators, m.names[i])
+			}
 		}
 	}
 
+	setOperators(newG1, operators)
+	setOperators(newG2, operators)
 	return newG1, newG2
 }
 
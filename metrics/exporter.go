@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tomcraven/goga"
+)
+
+// ContentType - the content type of the Prometheus text exposition format served by an Exporter
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Exporter -
+// Implements goga.Monitor by keeping metrics of the runs of a GeneticAlgorithm
+// and serving them in the Prometheus text exposition format. Add it to the
+// GeneticAlgorithm's Monitors and either Listen for scrapes of /metrics or
+// use it as an http.Handler on an existing server.
+// Counters keep counting across runs, gauges describe the most recent generation.
+// A stalled run can be alerted on with goga_last_generation_timestamp_seconds or
+// goga_stagnant_generations
+type Exporter struct {
+	mutex sync.Mutex
+
+	generations          *counter
+	evaluations          *counter
+	evaluationsPerSecond *gauge
+	eliteFitness         *gauge
+	meanFitness          *gauge
+	diversity            *gauge
+	workerUtilisation    *gauge
+	stagnantGenerations  *gauge
+	lastGeneration       *gauge
+	simulationLatency    *histogram
+	metrics              []metric
+
+	// Measured since the previous generation
+	busy            time.Duration
+	lastEvaluations int
+	lastElapsed     time.Duration
+
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewExporter returns an exporter whose simulation latency histogram uses DefaultBuckets
+func NewExporter() *Exporter {
+	return NewExporterWithBuckets(DefaultBuckets)
+}
+
+// NewExporterWithBuckets returns an exporter whose simulation latency histogram
+// uses 'buckets', the increasing upper bounds of its buckets in seconds
+func NewExporterWithBuckets(buckets []float64) *Exporter {
+	e := &Exporter{
+		generations:          &counter{name: "goga_generations_total", help: "The number of generations completed."},
+		evaluations:          &counter{name: "goga_evaluations_total", help: "The number of genomes simulated."},
+		evaluationsPerSecond: &gauge{name: "goga_evaluations_per_second", help: "The rate genomes were simulated at during the last generation."},
+		eliteFitness:         &gauge{name: "goga_elite_fitness", help: "The fitness of the last generation's elite."},
+		meanFitness:          &gauge{name: "goga_mean_fitness", help: "The mean fitness of the last generation's population."},
+		diversity:            &gauge{name: "goga_diversity", help: "The diversity of the last generation's population, from 0 to 1."},
+		workerUtilisation:    &gauge{name: "goga_worker_utilisation", help: "The fraction of the last generation the simulation goroutines spent simulating."},
+		stagnantGenerations:  &gauge{name: "goga_stagnant_generations", help: "The number of generations since the best fitness improved."},
+		lastGeneration:       &gauge{name: "goga_last_generation_timestamp_seconds", help: "The unix time the last generation completed at."},
+		simulationLatency:    newHistogram("goga_simulation_duration_seconds", "How long each genome took to simulate.", buckets),
+	}
+	e.metrics = []metric{
+		e.generations, e.evaluations, e.evaluationsPerSecond,
+		e.eliteFitness, e.meanFitness, e.diversity,
+		e.workerUtilisation, e.stagnantGenerations, e.lastGeneration,
+		e.simulationLatency,
+	}
+	return e
+}
+
+// OnSimulated records how long a genome took to simulate
+func (e *Exporter) OnSimulated(g goga.Genome, duration time.Duration) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.simulationLatency.observe(duration.Seconds())
+	e.busy += duration
+}
+
+// OnGeneration updates the metrics from the state of the run and its population
+func (e *Exporter) OnGeneration(state goga.RunState, population []goga.Genome) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if state.Generation <= 1 {
+		// A new run
+		e.lastEvaluations, e.lastElapsed = 0, 0
+	}
+
+	evaluations := state.Evaluations - e.lastEvaluations
+	elapsed := (state.Elapsed - e.lastElapsed).Seconds()
+	e.generations.add(1)
+	e.evaluations.add(float64(evaluations))
+	if elapsed > 0 {
+		e.evaluationsPerSecond.set(float64(evaluations) / elapsed)
+		workers := float64(state.ParallelSimulations)
+		e.workerUtilisation.set(math.Min(1, e.busy.Seconds()/(elapsed*math.Max(1, workers))))
+	}
+
+	if state.Elite != nil {
+		e.eliteFitness.set(float64(state.Elite.GetFitness()))
+	}
+	e.meanFitness.set(meanFitness(population))
+	e.diversity.set(goga.Diversity(population))
+	e.stagnantGenerations.set(float64(state.StagnantGenerations))
+	e.lastGeneration.set(float64(time.Now().UnixNano()) / float64(time.Second))
+
+	e.busy = 0
+	e.lastEvaluations, e.lastElapsed = state.Evaluations, state.Elapsed
+}
+
+func meanFitness(population []goga.Genome) float64 {
+	if len(population) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, g := range population {
+		total += float64(g.GetFitness())
+	}
+	return total / float64(len(population))
+}
+
+// WriteText writes every metric to 'w' in the Prometheus text exposition format
+func (e *Exporter) WriteText(w io.Writer) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for _, m := range e.metrics {
+		if err := m.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeHTTP serves the metrics to a scrape
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	e.WriteText(w)
+}
+
+// Listen starts serving the metrics at /metrics on 'address' in the background,
+// e.g. "127.0.0.1:9090"
+func (e *Exporter) Listen(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	e.listener = listener
+	e.server = &http.Server{Handler: mux}
+	go e.server.Serve(listener)
+	return nil
+}
+
+// Addr returns the address the exporter is listening on
+func (e *Exporter) Addr() net.Addr {
+	if e.listener == nil {
+		return nil
+	}
+	return e.listener.Addr()
+}
+
+// Close stops serving the metrics
+func (e *Exporter) Close() error {
+	if e.server == nil {
+		return nil
+	}
+	return e.server.Close()
+}
@@ -0,0 +1,164 @@
+package metrics_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tomcraven/goga"
+	"github.com/tomcraven/goga/metrics"
+	. "gopkg.in/check.v1"
+)
+
+type ExporterSuite struct {
+}
+
+var _ = Suite(&ExporterSuite{})
+
+func helperGenome(fitness int, bits ...int) goga.Genome {
+	b := goga.Bitset{}
+	b.Create(len(bits))
+	for i, bit := range bits {
+		b.Set(i, bit)
+	}
+	g := goga.NewGenome(b)
+	g.SetFitness(fitness)
+	return g
+}
+
+// helperMetrics returns the value of every sample written by 'exporter', keyed by name and labels
+func helperMetrics(t *C, exporter *metrics.Exporter) map[string]string {
+	var buffer bytes.Buffer
+	t.Assert(exporter.WriteText(&buffer), IsNil)
+	return helperParse(buffer.String())
+}
+
+func helperParse(text string) map[string]string {
+	ret := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		space := strings.LastIndex(line, " ")
+		ret[line[:space]] = line[space+1:]
+	}
+	return ret
+}
+
+func (s *ExporterSuite) TestShouldWriteTextFormat(t *C) {
+	exporter := metrics.NewExporterWithBuckets([]float64{0.01, 0.1})
+	exporter.OnSimulated(nil, 5*time.Millisecond)
+	exporter.OnSimulated(nil, 50*time.Millisecond)
+	exporter.OnSimulated(nil, time.Second)
+
+	population := []goga.Genome{helperGenome(1, 0, 0), helperGenome(3, 1, 0)}
+	exporter.OnGeneration(goga.RunState{
+		Generation:          1,
+		Evaluations:         3,
+		Elapsed:             time.Second,
+		Elite:               population[1],
+		StagnantGenerations: 2,
+		ParallelSimulations: 2,
+	}, population)
+
+	var buffer bytes.Buffer
+	t.Assert(exporter.WriteText(&buffer), IsNil)
+	text := buffer.String()
+	t.Assert(strings.HasPrefix(text, "# HELP goga_generations_total The number of generations completed.\n"+
+		"# TYPE goga_generations_total counter\n"+
+		"goga_generations_total 1\n"), Equals, true)
+	t.Assert(strings.Contains(text, "# TYPE goga_simulation_duration_seconds histogram\n"+
+		"goga_simulation_duration_seconds_bucket{le=\"0.01\"} 1\n"+
+		"goga_simulation_duration_seconds_bucket{le=\"0.1\"} 2\n"+
+		"goga_simulation_duration_seconds_bucket{le=\"+Inf\"} 3\n"+
+		"goga_simulation_duration_seconds_sum 1.055\n"+
+		"goga_simulation_duration_seconds_count 3\n"), Equals, true)
+
+	values := helperParse(text)
+	t.Assert(values["goga_evaluations_total"], Equals, "3")
+	t.Assert(values["goga_evaluations_per_second"], Equals, "3")
+	t.Assert(values["goga_elite_fitness"], Equals, "3")
+	t.Assert(values["goga_mean_fitness"], Equals, "2")
+	t.Assert(values["goga_diversity"], Equals, "0.5")
+	t.Assert(values["goga_worker_utilisation"], Equals, "0.5275")
+	t.Assert(values["goga_stagnant_generations"], Equals, "2")
+	t.Assert(values["goga_last_generation_timestamp_seconds"] != "0", Equals, true)
+}
+
+func (s *ExporterSuite) TestShouldMeasureEachGeneration(t *C) {
+	exporter := metrics.NewExporter()
+	population := []goga.Genome{helperGenome(1, 1)}
+	state := goga.RunState{Generation: 1, Evaluations: 10, Elapsed: time.Second, Elite: population[0], ParallelSimulations: 1}
+	exporter.OnGeneration(state, population)
+
+	state.Generation, state.Evaluations, state.Elapsed = 2, 30, 3*time.Second
+	exporter.OnGeneration(state, population)
+	values := helperMetrics(t, exporter)
+	t.Assert(values["goga_generations_total"], Equals, "2")
+	t.Assert(values["goga_evaluations_total"], Equals, "30")
+	t.Assert(values["goga_evaluations_per_second"], Equals, "10")
+	t.Assert(values["goga_worker_utilisation"], Equals, "0")
+
+	// Counters keep counting when a new run starts
+	state.Generation, state.Evaluations, state.Elapsed = 1, 5, time.Second
+	exporter.OnGeneration(state, population)
+	values = helperMetrics(t, exporter)
+	t.Assert(values["goga_generations_total"], Equals, "3")
+	t.Assert(values["goga_evaluations_total"], Equals, "35")
+	t.Assert(values["goga_evaluations_per_second"], Equals, "5")
+}
+
+type MySimulatorSlowCountOnes struct {
+	goga.NullSimulator
+}
+
+func (ms *MySimulatorSlowCountOnes) Simulate(g goga.Genome) {
+	time.Sleep(time.Millisecond)
+	bits := g.GetBits()
+	fitness := 0
+	for i := 0; i < bits.GetSize(); i++ {
+		fitness += bits.Get(i)
+	}
+	g.SetFitness(fitness)
+}
+
+func (s *ExporterSuite) TestShouldServeMetricsOfARun(t *C) {
+	exporter := metrics.NewExporter()
+	t.Assert(exporter.Listen("127.0.0.1:0"), IsNil)
+	defer exporter.Close()
+
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Monitors = []goga.Monitor{exporter}
+	genAlgo.Simulator = &MySimulatorSlowCountOnes{}
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(16)
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.UniformCrossover},
+		{P: 1, F: goga.Mutate},
+	})
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: goga.Roulette},
+	})
+	genAlgo.Terminator = goga.MaxGenerations(5)
+	genAlgo.Init(10, 2)
+	genAlgo.Simulate()
+
+	response, err := http.Get("http://" + exporter.Addr().String() + "/metrics")
+	t.Assert(err, IsNil)
+	defer response.Body.Close()
+	t.Assert(response.StatusCode, Equals, http.StatusOK)
+	t.Assert(response.Header.Get("Content-Type"), Equals, metrics.ContentType)
+
+	body, err := io.ReadAll(response.Body)
+	t.Assert(err, IsNil)
+	values := helperParse(string(body))
+	state := genAlgo.GetRunState()
+	t.Assert(values["goga_generations_total"], Equals, "5")
+	t.Assert(values["goga_evaluations_total"], Equals, "50")
+	t.Assert(values["goga_simulation_duration_seconds_count"], Equals, "50")
+	t.Assert(values[`goga_simulation_duration_seconds_bucket{le="0.0005"}`], Equals, "0")
+	t.Assert(values["goga_elite_fitness"], Equals, strconv.Itoa(state.Elite.GetFitness()))
+	t.Assert(values["goga_worker_utilisation"] != "0", Equals, true)
+}
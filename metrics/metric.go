@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// DefaultBuckets - the upper bounds, in seconds, of the simulation latency histogram's buckets
+var DefaultBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metric - a named value written in the Prometheus text exposition format
+type metric interface {
+	write(w io.Writer) error
+}
+
+// formatValue formats 'v' as the exposition format expects, including infinities and NaN
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func writeHeader(w io.Writer, name, help, metricType string) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+	return err
+}
+
+// counter - a value that only goes up
+type counter struct {
+	name  string
+	help  string
+	value float64
+}
+
+func (c *counter) add(v float64) {
+	if v > 0 {
+		c.value += v
+	}
+}
+
+func (c *counter) write(w io.Writer) error {
+	if err := writeHeader(w, c.name, c.help, "counter"); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s %s\n", c.name, formatValue(c.value))
+	return err
+}
+
+// gauge - a value that can go up and down
+type gauge struct {
+	name  string
+	help  string
+	value float64
+}
+
+func (g *gauge) set(v float64) {
+	g.value = v
+}
+
+func (g *gauge) write(w io.Writer) error {
+	if err := writeHeader(w, g.name, g.help, "gauge"); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s %s\n", g.name, formatValue(g.value))
+	return err
+}
+
+// histogram - counts observations in buckets of increasing upper bounds,
+// each bucket's count includes every observation in the buckets below it
+type histogram struct {
+	name    string
+	help    string
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(name, help string, buckets []float64) *histogram {
+	return &histogram{
+		name:    name,
+		help:    help,
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, bound := range h.buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) write(w io.Writer) error {
+	if err := writeHeader(w, h.name, h.help, "histogram"); err != nil {
+		return err
+	}
+	for i, bound := range h.buckets {
+		if _, err := fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %v\n", h.name, formatValue(bound), h.counts[i]); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %v\n%s_sum %s\n%s_count %v\n",
+		h.name, h.count, h.name, formatValue(h.sum), h.name, h.count)
+	return err
+}
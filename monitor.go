@@ -0,0 +1,23 @@
+package goga
+
+import "time"
+
+// Monitor - an interface to an object that measures a run, such as an exporter of metrics
+// * OnSimulated - is passed each genome once it has been simulated and how long
+// the Simulator took, it is called from the simulation goroutines so must be
+// safe for concurrent use
+// * OnGeneration - is passed the state of the run and its population after each
+// generation, on the goroutine that called Simulate. Neither should be modified
+type Monitor interface {
+	OnSimulated(Genome, time.Duration)
+	OnGeneration(RunState, []Genome)
+}
+
+// monitorGeneration passes the state of the run to every monitor
+func (ga *GeneticAlgorithm) monitorGeneration() {
+	// Only this goroutine writes the run state so no lock is needed to read it
+	state := ga.runState
+	for _, monitor := range ga.Monitors {
+		monitor.OnGeneration(state, ga.population)
+	}
+}
@@ -0,0 +1,63 @@
+package goga_test
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type MonitorSuite struct {
+}
+
+var _ = Suite(&MonitorSuite{})
+
+type MyMonitorRecorder struct {
+	mutex        sync.Mutex
+	numSimulated int
+	states       []goga.RunState
+	populations  []int
+}
+
+func (m *MyMonitorRecorder) OnSimulated(g goga.Genome, duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.numSimulated++
+}
+
+func (m *MyMonitorRecorder) OnGeneration(state goga.RunState, population []goga.Genome) {
+	m.states = append(m.states, state)
+	m.populations = append(m.populations, len(population))
+}
+
+func (s *MonitorSuite) TestShouldMonitorARun(t *C) {
+	for _, steadyState := range []bool{false, true} {
+		monitor := &MyMonitorRecorder{}
+		genAlgo := goga.NewGeneticAlgorithm()
+		genAlgo.Monitors = []goga.Monitor{monitor}
+		genAlgo.SteadyState = steadyState
+		genAlgo.Simulator = &MySimulatorCountOnes{}
+		genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(16)
+		genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+			{P: 1, F: goga.UniformCrossover},
+		})
+		genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+			{P: 1, F: goga.Roulette},
+		})
+		genAlgo.Terminator = goga.MaxGenerations(4)
+		genAlgo.Init(10, 3)
+		genAlgo.Simulate()
+
+		t.Assert(monitor.states, HasLen, 4)
+		for i, state := range monitor.states {
+			t.Assert(state.Generation, Equals, i+1)
+			t.Assert(state.Evaluations, Equals, (i+1)*10)
+			t.Assert(state.ParallelSimulations, Equals, 3)
+			t.Assert(monitor.populations[i], Equals, 10)
+		}
+
+		// Steady state mode can have simulations in flight when it exits
+		t.Assert(monitor.numSimulated >= 40, IsTrue)
+	}
+}
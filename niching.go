@@ -0,0 +1,190 @@
+package goga
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// DistanceFunc - returns how far apart two genomes are, used to decide
+// which genomes share a niche
+type DistanceFunc func(a, b Genome) float64
+
+// HammingDistance is a DistanceFunc that returns the number of bits that differ
+// between two genomes, bits past the end of the shorter genome all differ
+func HammingDistance(a, b Genome) float64 {
+	aBits, bBits := a.GetBits(), b.GetBits()
+	distance := max(aBits.GetSize(), bBits.GetSize()) - min(aBits.GetSize(), bBits.GetSize())
+	return float64(distance + hammingDistance(aBits, bBits))
+}
+
+// Niching - an interface to an object that adjusts the fitness of a simulated
+// population before parents are selected from it, so that the population spreads
+// over several peaks rather than collapsing on to one. The elite, the hall of fame
+// and the terminator all see the fitness the Simulator gave before it is adjusted,
+// and it is restored once the next generation has been bred.
+// Niching is only applied in generational mode
+type Niching interface {
+	Go([]Genome)
+}
+
+// NullNiching - a null implementation of the Niching interface
+type NullNiching struct {
+}
+
+// Go - null implementation of Niching's 'Go'
+func (nn *NullNiching) Go([]Genome) {
+}
+
+type fitnessSharing struct {
+	radius   float64
+	alpha    float64
+	distance DistanceFunc
+}
+
+// NewFitnessSharing returns a Niching that divides the fitness of each genome by
+// the number of genomes in its niche, so crowded peaks become less attractive.
+// Genomes closer than 'radius' share a niche, each counting
+// 1 - (distance / radius) ^ 'alpha' towards the niche count
+func NewFitnessSharing(radius, alpha float64, distance DistanceFunc) Niching {
+	return &fitnessSharing{
+		radius:   radius,
+		alpha:    alpha,
+		distance: distance,
+	}
+}
+
+func (fs *fitnessSharing) Go(population []Genome) {
+	shared := make([]int, len(population))
+	for i := range population {
+		nicheCount := 0.0
+		for j := range population {
+			d := fs.distance(population[i], population[j])
+			if d < fs.radius {
+				nicheCount += 1 - math.Pow(d/fs.radius, fs.alpha)
+			}
+		}
+		shared[i] = int(math.Round(float64(population[i].GetFitness()) / math.Max(nicheCount, 1)))
+	}
+
+	for i, g := range population {
+		g.SetFitness(shared[i])
+	}
+}
+
+type clearing struct {
+	radius   float64
+	capacity int
+	distance DistanceFunc
+}
+
+// NewClearing returns a Niching that keeps the fitness of the 'capacity' fittest
+// genomes of each niche and sets the fitness of the rest of the niche to 0.
+// Each niche is centred on the fittest genome not already in a niche, and holds
+// every other genome closer to it than 'radius'
+func NewClearing(radius float64, capacity int, distance DistanceFunc) Niching {
+	return &clearing{
+		radius:   radius,
+		capacity: capacity,
+		distance: distance,
+	}
+}
+
+func (c *clearing) Go(population []Genome) {
+	indices := make([]int, len(population))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return population[indices[i]].GetFitness() > population[indices[j]].GetFitness()
+	})
+
+	cleared := make([]bool, len(population))
+	for i, centre := range indices {
+		if cleared[centre] || population[centre].GetFitness() <= 0 {
+			continue
+		}
+
+		winners := 1
+		for _, other := range indices[i+1:] {
+			if cleared[other] || c.distance(population[centre], population[other]) >= c.radius {
+				continue
+			}
+			if winners < c.capacity {
+				winners++
+			} else {
+				cleared[other] = true
+			}
+		}
+	}
+
+	for i, g := range population {
+		if cleared[i] {
+			g.SetFitness(0)
+		}
+	}
+}
+
+// Crowding - an interface to an object that decides which of two parents and
+// the two offspring bred from them survive into the next generation, so that
+// offspring only replace genomes similar to themselves.
+// Offspring are only crowded with siblings bred at the same time, a Reproducer's
+// offspring are paired in order with the first parents of their family and the
+// last of an odd number competes with its parent alone, passed as both p1 and p2.
+// Crowding is only applied in generational mode
+type Crowding interface {
+	Go(p1, p2, c1, c2 Genome) (Genome, Genome)
+}
+
+// NullCrowding - a null implementation of the Crowding interface
+type NullCrowding struct {
+}
+
+// Go - null implementation of Crowding's 'Go', the offspring always survive
+func (nc *NullCrowding) Go(p1, p2, c1, c2 Genome) (Genome, Genome) {
+	return c1, c2
+}
+
+type crowding struct {
+	distance      DistanceFunc
+	probabilistic bool
+}
+
+// NewDeterministicCrowding returns a Crowding that pairs each offspring with the
+// parent closest to it, the offspring replaces its parent if it is at least as fit
+func NewDeterministicCrowding(distance DistanceFunc) Crowding {
+	return &crowding{distance: distance}
+}
+
+// NewProbabilisticCrowding returns a Crowding that pairs each offspring with the
+// parent closest to it, the offspring replaces its parent with a probability of
+// its share of their combined fitness
+func NewProbabilisticCrowding(distance DistanceFunc) Crowding {
+	return &crowding{distance: distance, probabilistic: true}
+}
+
+func (c *crowding) Go(p1, p2, c1, c2 Genome) (Genome, Genome) {
+	if c.distance(p1, c1)+c.distance(p2, c2) > c.distance(p1, c2)+c.distance(p2, c1) {
+		c1, c2 = c2, c1
+	}
+	return c.compete(p1, c1), c.compete(p2, c2)
+}
+
+// compete returns the survivor of 'parent' and 'child'
+func (c *crowding) compete(parent, child Genome) Genome {
+	parentFitness, childFitness := parent.GetFitness(), child.GetFitness()
+
+	childWins := childFitness >= parentFitness
+	if c.probabilistic {
+		total := float64(max(parentFitness, 0) + max(childFitness, 0))
+		childWins = rand.Float64() < 0.5
+		if total > 0 {
+			childWins = rand.Float64() < float64(max(childFitness, 0))/total
+		}
+	}
+
+	if childWins {
+		return child
+	}
+	return CopyGenome(parent)
+}
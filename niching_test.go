@@ -0,0 +1,223 @@
+package goga_test
+
+import (
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type NichingSuite struct {
+}
+
+var _ = Suite(&NichingSuite{})
+
+func helperFitnesses(genomes []goga.Genome) []int {
+	ret := make([]int, len(genomes))
+	for i, g := range genomes {
+		ret[i] = g.GetFitness()
+	}
+	return ret
+}
+
+func (s *NichingSuite) TestShouldMeasureHammingDistance(t *C) {
+	a := helperGenomeFromString(t, "0101", 0)
+	t.Assert(goga.HammingDistance(a, helperGenomeFromString(t, "0101", 0)), Equals, 0.0)
+	t.Assert(goga.HammingDistance(a, helperGenomeFromString(t, "1100", 0)), Equals, 2.0)
+	t.Assert(goga.HammingDistance(a, helperGenomeFromString(t, "010111", 0)), Equals, 2.0)
+}
+
+func (s *NichingSuite) TestShouldShareFitness(t *C) {
+	population := []goga.Genome{
+		helperGenomeFromString(t, "0000", 100),
+		helperGenomeFromString(t, "0001", 100),
+		helperGenomeFromString(t, "1111", 100),
+	}
+
+	// The first two genomes are 1 apart, each counts 1 - (1/2)^1 towards the other's niche
+	goga.NewFitnessSharing(2, 1, goga.HammingDistance).Go(population)
+	t.Assert(helperFitnesses(population), DeepEquals, []int{67, 67, 100})
+}
+
+func (s *NichingSuite) TestShouldClearNiches(t *C) {
+	population := []goga.Genome{
+		helperGenomeFromString(t, "0000", 50),
+		helperGenomeFromString(t, "0001", 100),
+		helperGenomeFromString(t, "0011", 70),
+		helperGenomeFromString(t, "1111", 10),
+		helperGenomeFromString(t, "1110", 20),
+	}
+
+	goga.NewClearing(2, 1, goga.HammingDistance).Go(population)
+	t.Assert(helperFitnesses(population), DeepEquals, []int{0, 100, 0, 0, 20})
+}
+
+func (s *NichingSuite) TestShouldKeepTheCapacityOfEachNiche(t *C) {
+	population := []goga.Genome{
+		helperGenomeFromString(t, "0000", 50),
+		helperGenomeFromString(t, "0001", 100),
+		helperGenomeFromString(t, "0011", 70),
+	}
+
+	goga.NewClearing(3, 2, goga.HammingDistance).Go(population)
+	t.Assert(helperFitnesses(population), DeepEquals, []int{0, 100, 70})
+}
+
+func (s *NichingSuite) TestShouldReplaceClosestParentWithDeterministicCrowding(t *C) {
+	crowding := goga.NewDeterministicCrowding(goga.HammingDistance)
+
+	p1, p2 := helperGenomeFromString(t, "0000", 10), helperGenomeFromString(t, "1111", 10)
+	c1, c2 := helperGenomeFromString(t, "1110", 20), helperGenomeFromString(t, "0001", 5)
+
+	// c1 is closest to p2 and fitter, c2 is closest to p1 and less fit
+	s1, s2 := crowding.Go(p1, p2, c1, c2)
+	t.Assert(helperGenomeStrings([]goga.Genome{s1, s2}), DeepEquals, []string{"0000", "1110"})
+	t.Assert(helperFitnesses([]goga.Genome{s1, s2}), DeepEquals, []int{10, 20})
+
+	// Surviving parents are copies
+	t.Assert(s1 == p1, Equals, false)
+}
+
+func (s *NichingSuite) TestShouldReplaceParentsWithProbabilisticCrowding(t *C) {
+	crowding := goga.NewProbabilisticCrowding(goga.HammingDistance)
+
+	p1, p2 := helperGenomeFromString(t, "0000", 0), helperGenomeFromString(t, "1111", 10)
+	c1, c2 := helperGenomeFromString(t, "0001", 10), helperGenomeFromString(t, "1110", 0)
+
+	childWins := 0
+	for i := 0; i < 100; i++ {
+		s1, s2 := crowding.Go(p1, p2, c1, c2)
+
+		// A genome with no fitness never beats one with some
+		t.Assert(s1, Equals, c1)
+		t.Assert(s2.GetBits().String(), Equals, "1111")
+
+		s1, _ = crowding.Go(p2, p2, c1, c1)
+		if s1 == c1 {
+			childWins++
+		}
+	}
+
+	// Equal fitness, the child wins half of the time
+	t.Assert(childWins > 25 && childWins < 75, IsTrue)
+}
+
+type MyNichingRecorder struct {
+	Calls int
+}
+
+func (mn *MyNichingRecorder) Go(population []goga.Genome) {
+	mn.Calls++
+	for _, g := range population {
+		g.SetFitness(1)
+	}
+}
+
+type MySelectorTotalFitness struct {
+	TotalFitness []int
+}
+
+func (ms *MySelectorTotalFitness) Go(genomes []goga.Genome, totalFitness int) goga.Genome {
+	ms.TotalFitness = append(ms.TotalFitness, totalFitness)
+	return genomes[0]
+}
+
+func (s *NichingSuite) TestShouldApplyNichingBeforeSelection(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	niching := &MyNichingRecorder{}
+	genAlgo.Niching = niching
+	selector := &MySelectorTotalFitness{}
+	genAlgo.Selector = selector
+	genAlgo.Simulator = &MySimulatorConstantFitness{Fitness: 7}
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(8)
+	genAlgo.Terminator = goga.MaxGenerations(3)
+	genAlgo.Init(10, 1)
+	genAlgo.Simulate()
+
+	t.Assert(niching.Calls, Equals, 2)
+
+	// Selection sees the adjusted fitness, everything else sees the simulated fitness
+	for _, total := range selector.TotalFitness {
+		t.Assert(total, Equals, 10)
+	}
+	t.Assert(genAlgo.GetRunState().EliteHistory, DeepEquals, []int{7, 7, 7})
+	for _, g := range genAlgo.GetPopulation() {
+		t.Assert(g.GetFitness(), Equals, 7)
+	}
+}
+
+type MyCrowdingKeepParents struct {
+	Calls int
+}
+
+func (mc *MyCrowdingKeepParents) Go(p1, p2, c1, c2 goga.Genome) (goga.Genome, goga.Genome) {
+	mc.Calls++
+	return goga.CopyGenome(p1), goga.CopyGenome(p2)
+}
+
+func (s *NichingSuite) TestShouldCrowdEveryPairOfOffspring(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	crowding := &MyCrowdingKeepParents{}
+	genAlgo.Crowding = crowding
+	genAlgo.Simulator = &MySimulatorConstantFitness{Fitness: 1}
+	genAlgo.BitsetCreate = goga.NewConstantBitsetCreate(8, 0)
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.Mutate},
+	})
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: goga.Roulette},
+	})
+	genAlgo.Terminator = goga.MaxGenerations(3)
+	genAlgo.Init(5, 1)
+	genAlgo.Simulate()
+
+	// Three pairs a generation, the last with only one offspring
+	t.Assert(crowding.Calls, Equals, 6)
+
+	// Every offspring was mutated, but only the parents survived
+	for _, g := range genAlgo.GetPopulation() {
+		t.Assert(g.GetBits().String(), Equals, "00000000")
+	}
+}
+
+type MyCrowdingRecorder struct {
+	Calls     int
+	LoneCalls int
+}
+
+func (mc *MyCrowdingRecorder) Go(p1, p2, c1, c2 goga.Genome) (goga.Genome, goga.Genome) {
+	mc.Calls++
+	if p1 == p2 && c1 == c2 {
+		mc.LoneCalls++
+	}
+	return c1, c2
+}
+
+func (s *NichingSuite) TestShouldCrowdOffspringWithinTheirFamily(t *C) {
+	for _, test := range []struct {
+		reproducer goga.Reproducer
+		calls      int
+		loneCalls  int
+	}{
+		// Families of 3, a pair and a lone offspring each
+		{goga.NewReproducer(3, 3, goga.DiagonalCrossover), 4, 2},
+		// Families of 1, every offspring is alone
+		{goga.NewReproducer(4, 1, goga.GenePoolRecombination), 6, 6},
+		// Families of 4, two pairs each, the last family is cut short
+		{goga.NewReproducer(4, 4, goga.DiagonalCrossover), 3, 0},
+	} {
+		genAlgo := goga.NewGeneticAlgorithm()
+		crowding := &MyCrowdingRecorder{}
+		genAlgo.Crowding = crowding
+		genAlgo.Simulator = &MySimulatorConstantFitness{Fitness: 1}
+		genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(8)
+		genAlgo.Reproducer = test.reproducer
+		genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+			{P: 1, F: goga.Roulette},
+		})
+		genAlgo.Terminator = goga.MaxGenerations(2)
+		genAlgo.Init(6, 1)
+		genAlgo.Simulate()
+
+		t.Assert(crowding.Calls, Equals, test.calls)
+		t.Assert(crowding.LoneCalls, Equals, test.loneCalls)
+	}
+}
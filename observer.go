@@ -0,0 +1,54 @@
+package goga
+
+// EventType - the kind of an Event
+type EventType string
+
+// The events a GeneticAlgorithm reports to its observers
+const (
+	// EventConverged - the population has converged, see Convergence
+	EventConverged EventType = "converged"
+	// EventReseeded - part of the next generation will be newly created genomes
+	EventReseeded EventType = "reseeded"
+	// EventHypermutationStarted - offspring will be hypermutated
+	EventHypermutationStarted EventType = "hypermutation started"
+	// EventHypermutationEnded - offspring are no longer hypermutated
+	EventHypermutationEnded EventType = "hypermutation ended"
+	// EventRestarted - the whole of the next generation will be newly created genomes
+	EventRestarted EventType = "restarted"
+)
+
+// Event - something that happened during a run
+// * Type - the kind of event
+// * Generation - the generation the event happened after
+// * Message - a human readable description of the event
+type Event struct {
+	Type       EventType
+	Generation int
+	Message    string
+}
+
+// Observer - an interface to an object that is told about the events of a run.
+// Observers are called on the goroutine that called Simulate
+type Observer interface {
+	OnEvent(Event)
+}
+
+// ObserverFunc - allows an ordinary function to be used as an Observer
+type ObserverFunc func(Event)
+
+// OnEvent calls the function
+func (f ObserverFunc) OnEvent(event Event) {
+	f(event)
+}
+
+// notify passes an event to every observer
+func (ga *GeneticAlgorithm) notify(eventType EventType, message string) {
+	event := Event{
+		Type:       eventType,
+		Generation: ga.runState.Generation,
+		Message:    message,
+	}
+	for _, observer := range ga.Observers {
+		observer.OnEvent(event)
+	}
+}
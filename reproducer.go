@@ -0,0 +1,210 @@
+package goga
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// Reproducer - a generalisation of Mater that breeds a fixed number of offspring
+// from a fixed number of parents
+// * NumParents - the number of parents Go is passed, each picked by the Selector
+// * NumOffspring - the number of offspring Go returns
+// * Go - breeds new offspring from the parents, the parents must not be modified
+// * OnElite - is passed the elite of each generation
+type Reproducer interface {
+	NumParents() int
+	NumOffspring() int
+	Go([]Genome) []Genome
+	OnElite(Genome)
+}
+
+type materReproducer struct {
+	mater Mater
+}
+
+// NewMaterReproducer returns a Reproducer that breeds two offspring from two parents with 'mater'
+func NewMaterReproducer(mater Mater) Reproducer {
+	return &materReproducer{mater: mater}
+}
+
+func (mr *materReproducer) NumParents() int {
+	return 2
+}
+
+func (mr *materReproducer) NumOffspring() int {
+	return 2
+}
+
+func (mr *materReproducer) Go(parents []Genome) []Genome {
+	g1, g2 := mr.mater.Go(parents[0], parents[1])
+	return []Genome{g1, g2}
+}
+
+func (mr *materReproducer) OnElite(elite Genome) {
+	mr.mater.OnElite(elite)
+}
+
+type reproducer struct {
+	numParents   int
+	numOffspring int
+	f            func([]Genome) []Genome
+	name         string
+}
+
+// NewReproducer returns a Reproducer that passes 'numParents' parents to 'f',
+// which must return 'numOffspring' offspring. Both must be at least 1
+// i.e.
+// NewReproducer(4, 4, DiagonalCrossover)
+// NewReproducer(10, 1, GenePoolRecombination)
+func NewReproducer(numParents, numOffspring int, f func([]Genome) []Genome) Reproducer {
+	checkReproducerCounts(numParents, numOffspring)
+	return &reproducer{
+		numParents:   numParents,
+		numOffspring: numOffspring,
+		f:            f,
+		name:         operatorName("", f),
+	}
+}
+
+func (r *reproducer) NumParents() int {
+	return r.numParents
+}
+
+func (r *reproducer) NumOffspring() int {
+	return r.numOffspring
+}
+
+func (r *reproducer) Go(parents []Genome) []Genome {
+	offspring := r.f(parents)
+	if recordsLineage(parents[0]) {
+		for _, g := range offspring {
+			setOperators(g, []string{r.name})
+		}
+	}
+	return offspring
+}
+
+func (r *reproducer) OnElite(Genome) {
+}
+
+// shortestBits returns the size of the smallest bitset of 'genomes'
+func shortestBits(genomes []Genome) int {
+	ret := genomes[0].GetBits().GetSize()
+	for _, g := range genomes[1:] {
+		ret = min(ret, g.GetBits().GetSize())
+	}
+	return ret
+}
+
+// DiagonalCrossover -
+// Accepts k parents, cuts them at the same k - 1 random points and returns k offspring,
+// each made of one segment from every parent with offspring 'i' taking segment 's'
+// from parent (i + s) % k. Offspring are as long as the shortest parent
+// i.e.
+// input genomes of:
+// 000000, 111111 and 222222 (using 2 to show the third parent's bits)
+// could produce output genomes of:
+// 001122, 112200 and 220011
+func DiagonalCrossover(parents []Genome) []Genome {
+	k := len(parents)
+	size := shortestBits(parents)
+
+	cuts := make([]int, k)
+	for i := 0; i < k-1; i++ {
+		cuts[i] = rand.Intn(size + 1)
+	}
+	cuts[k-1] = size
+	sort.Ints(cuts[:k-1])
+
+	offspring := make([]Genome, k)
+	for i := range offspring {
+		bits := Bitset{}
+		bits.Create(size)
+
+		start := 0
+		for segment, end := range cuts {
+			parentBits := parents[(i+segment)%k].GetBits()
+			for bit := start; bit < end; bit++ {
+				bits.Set(bit, parentBits.Get(bit))
+			}
+			start = end
+		}
+		offspring[i] = NewGenome(bits)
+	}
+	return offspring
+}
+
+// GenePoolRecombination -
+// Accepts any number of parents and returns one offspring where each bit is set
+// with the probability of it being set in the parents, so the offspring is sampled
+// from the pool of the parents' genes rather than from any pair of them.
+// The offspring is as long as the shortest parent
+// i.e.
+// input genomes of:
+// 0011, 0111, 0101 and 0001
+// could produce an output genome of:
+// 0101 where the last bit is always set and the first never is
+func GenePoolRecombination(parents []Genome) []Genome {
+	size := shortestBits(parents)
+
+	bits := Bitset{}
+	bits.Create(size)
+	for bit := 0; bit < size; bit++ {
+		ones := 0
+		for _, p := range parents {
+			ones += p.GetBits().Get(bit)
+		}
+		if rand.Intn(len(parents)) < ones {
+			bits.Set(bit, 1)
+		}
+	}
+	return []Genome{NewGenome(bits)}
+}
+
+// checkReproducerCounts panics if a reproducer would take no parents or breed no offspring
+func checkReproducerCounts(numParents, numOffspring int) {
+	if numParents < 1 {
+		panic("reproducer must take at least one parent")
+	}
+	if numOffspring < 1 {
+		panic("reproducer must breed at least one offspring")
+	}
+}
+
+// reproduce breeds offspring from 'selected', panicking if the reproducer does not
+// return as many as it declares as that would leave gaps in the new population
+func reproduce(reproducer Reproducer, selected []Genome) []Genome {
+	offspring := reproducer.Go(selected)
+	if len(offspring) != reproducer.NumOffspring() {
+		panic(fmt.Sprintf("reproducer returned %v offspring but declares %v", len(offspring), reproducer.NumOffspring()))
+	}
+	return offspring
+}
+
+// reproducer returns the Reproducer used to breed offspring,
+// the Mater is used when Reproducer is not set
+func (ga *GeneticAlgorithm) reproducer() Reproducer {
+	if ga.Reproducer != nil {
+		checkReproducerCounts(ga.Reproducer.NumParents(), ga.Reproducer.NumOffspring())
+		return ga.Reproducer
+	}
+	return NewMaterReproducer(ga.Mater)
+}
+
+// numOffspring returns the number of offspring bred each generation
+func (ga *GeneticAlgorithm) numOffspring() int {
+	if ga.NumOffspring > 0 {
+		return ga.NumOffspring
+	}
+	return ga.populationSize
+}
+
+// selectParents picks the parents of the next offspring
+func selectParents(selector Selector, population []Genome, totalFitness, numParents int) []Genome {
+	parents := make([]Genome, numParents)
+	for i := range parents {
+		parents[i] = selector.Go(population, totalFitness)
+	}
+	return parents
+}
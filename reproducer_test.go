@@ -0,0 +1,168 @@
+package goga_test
+
+import (
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type ReproducerSuite struct {
+}
+
+var _ = Suite(&ReproducerSuite{})
+
+func (s *ReproducerSuite) TestShouldDiagonallyCrossover(t *C) {
+	for i := 0; i < 100; i++ {
+		parents := []goga.Genome{
+			helperGenome(t, "00000000"),
+			helperGenome(t, "11111111"),
+			helperGenome(t, "00000000"),
+			helperGenome(t, "000000000000"),
+		}
+		offspring := goga.DiagonalCrossover(parents)
+		t.Assert(offspring, HasLen, 4)
+
+		// Every bit comes from a different parent, so only one offspring has each bit set
+		for bit := 0; bit < 8; bit++ {
+			ones := 0
+			for _, o := range offspring {
+				t.Assert(o.GetBits().GetSize(), Equals, 8)
+				ones += o.GetBits().Get(bit)
+			}
+			t.Assert(ones, Equals, 1)
+		}
+		t.Assert(helperGenomeStrings(parents[:2]), DeepEquals, []string{"00000000", "11111111"})
+	}
+}
+
+func (s *ReproducerSuite) TestShouldRecombineTheGenePool(t *C) {
+	parents := []goga.Genome{
+		helperGenome(t, "0011"),
+		helperGenome(t, "0111"),
+		helperGenome(t, "0101"),
+		helperGenome(t, "0001"),
+	}
+
+	secondBitSet := 0
+	for i := 0; i < 1000; i++ {
+		offspring := goga.GenePoolRecombination(parents)
+		t.Assert(offspring, HasLen, 1)
+
+		bits := offspring[0].GetBits()
+		t.Assert(bits.Get(0), Equals, 0)
+		t.Assert(bits.Get(3), Equals, 1)
+		secondBitSet += bits.Get(1)
+	}
+	t.Assert(secondBitSet > 400 && secondBitSet < 600, IsTrue)
+}
+
+func (s *ReproducerSuite) TestShouldAdaptMaters(t *C) {
+	reproducer := goga.NewMaterReproducer(goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.Mutate},
+	}))
+	t.Assert(reproducer.NumParents(), Equals, 2)
+	t.Assert(reproducer.NumOffspring(), Equals, 2)
+
+	offspring := reproducer.Go([]goga.Genome{helperGenome(t, "0000"), helperGenome(t, "1111")})
+	t.Assert(offspring, HasLen, 2)
+	t.Assert(helperCountOnes(offspring[0].GetBits()), Equals, 1)
+	t.Assert(offspring[1].GetBits().String(), Equals, "1111")
+}
+
+func (s *ReproducerSuite) TestShouldSelectEveryParent(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	selector := &MySelectorCounter{}
+	genAlgo.Selector = selector
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(8)
+	genAlgo.Reproducer = goga.NewReproducer(3, 3, goga.DiagonalCrossover)
+	genAlgo.Terminator = goga.MaxGenerations(3)
+	genAlgo.Init(10, 1)
+	genAlgo.Simulate()
+
+	// 4 sets of 3 parents a generation, the last 2 offspring do not fit in the population
+	t.Assert(selector.CallCount, Equals, 2*12)
+	t.Assert(genAlgo.GetPopulation(), HasLen, 10)
+}
+
+func (s *ReproducerSuite) TestShouldRejectInvalidCounts(t *C) {
+	t.Assert(func() { goga.NewReproducer(0, 1, goga.GenePoolRecombination) }, Panics, "reproducer must take at least one parent")
+	t.Assert(func() { goga.NewReproducer(3, 0, goga.DiagonalCrossover) }, Panics, "reproducer must breed at least one offspring")
+}
+
+func (s *ReproducerSuite) TestShouldRejectMissingOffspring(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Selector = &MySelectorCounter{}
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(8)
+	genAlgo.Reproducer = goga.NewReproducer(2, 2, func(parents []goga.Genome) []goga.Genome {
+		return []goga.Genome{goga.CopyGenome(parents[0])}
+	})
+	genAlgo.Terminator = goga.MaxGenerations(3)
+	genAlgo.Init(10, 1)
+
+	t.Assert(func() { genAlgo.Simulate() }, Panics, "reproducer returned 1 offspring but declares 2")
+}
+
+func (s *ReproducerSuite) TestShouldBreedSingleOffspringInParallel(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorCountOnes{}
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: goga.Roulette},
+	})
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(16)
+	genAlgo.Reproducer = goga.NewReproducer(5, 1, goga.GenePoolRecombination)
+	genAlgo.ParallelBreeding = true
+	genAlgo.Terminator = goga.MaxGenerations(5)
+	genAlgo.Init(11, 3)
+	genAlgo.Simulate()
+
+	t.Assert(genAlgo.GetRunState().Evaluations, Equals, 5*11)
+	for _, g := range genAlgo.GetPopulation() {
+		t.Assert(g.GetFitness(), Equals, helperCountOnes(g.GetBits()))
+	}
+}
+
+func (s *ReproducerSuite) TestShouldKeepTheFittestOffspring(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorIncreasingFitness{}
+	genAlgo.NumOffspring = 30
+	genAlgo.Terminator = goga.MaxGenerations(3)
+	genAlgo.Init(10, 1)
+	genAlgo.Simulate()
+
+	// Offspring are simulated in the order they are bred, so the last 10 are the fittest
+	t.Assert(genAlgo.GetRunState().Evaluations, Equals, 10+2*30)
+	t.Assert(helperFitnesses(genAlgo.GetPopulation()), DeepEquals, []int{70, 69, 68, 67, 66, 65, 64, 63, 62, 61})
+}
+
+func (s *ReproducerSuite) TestShouldKeepTheFittestParents(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorIncreasingFitness{}
+	genAlgo.NumOffspring = 4
+	genAlgo.Terminator = goga.MaxGenerations(2)
+	genAlgo.Init(6, 1)
+	genAlgo.Simulate()
+
+	// Too few offspring, so the fittest parents fill the rest of the population
+	t.Assert(helperFitnesses(genAlgo.GetPopulation()), DeepEquals, []int{10, 9, 8, 7, 6, 5})
+}
+
+type MySimulatorDecreasingFitness struct {
+	goga.NullSimulator
+	nextFitness int
+}
+
+func (ms *MySimulatorDecreasingFitness) Simulate(g goga.Genome) {
+	ms.nextFitness--
+	g.SetFitness(ms.nextFitness)
+}
+
+func (s *ReproducerSuite) TestShouldKeepParentsAndOffspring(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorDecreasingFitness{}
+	genAlgo.KeepParents = true
+	genAlgo.Terminator = goga.MaxGenerations(2)
+	genAlgo.Init(4, 1)
+	genAlgo.Simulate()
+
+	// Every offspring is less fit than every parent
+	t.Assert(helperFitnesses(genAlgo.GetPopulation()), DeepEquals, []int{-1, -2, -3, -4})
+}
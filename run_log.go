@@ -0,0 +1,358 @@
+package goga
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GenerationRecord - a summary of one generation of a run, as written by the run loggers
+// * Generation - the number of generations simulated, starting at 1
+// * EliteFitness - the fitness of the generation's elite
+// * EliteBits - the elite's bitset in the format produced by Bitset.Hex
+// * MeanFitness, MinFitness, MaxFitness, StdDevFitness - the fitness of the population
+// * Diversity - the Diversity of the population
+// * Evaluations - the number of genomes simulated so far
+// * Started - when the run started, which tells runs appended to the same log apart
+// * Elapsed - the time since the run started
+// * Duration - the time the generation took
+type GenerationRecord struct {
+	Generation    int           `json:"generation"`
+	EliteFitness  int           `json:"eliteFitness"`
+	EliteBits     string        `json:"eliteBits"`
+	MeanFitness   float64       `json:"meanFitness"`
+	MinFitness    int           `json:"minFitness"`
+	MaxFitness    int           `json:"maxFitness"`
+	StdDevFitness float64       `json:"stdDevFitness"`
+	Diversity     float64       `json:"diversity"`
+	Evaluations   int           `json:"evaluations"`
+	Started       time.Time     `json:"started"`
+	Elapsed       time.Duration `json:"elapsedNs"`
+	Duration      time.Duration `json:"durationNs"`
+}
+
+// EliteBitset returns the bitset of the elite the record was made from
+func (r *GenerationRecord) EliteBitset() (Bitset, error) {
+	return ParseBitsetHex(r.EliteBits)
+}
+
+// csvColumns - the header of a CSV log, the names match the JSON keys of a GenerationRecord
+var csvColumns = []string{
+	"generation", "eliteFitness", "eliteBits",
+	"meanFitness", "minFitness", "maxFitness", "stdDevFitness", "diversity",
+	"evaluations", "started", "elapsedNs", "durationNs",
+}
+
+func (r *GenerationRecord) csvRow() []string {
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return []string{
+		strconv.Itoa(r.Generation), strconv.Itoa(r.EliteFitness), r.EliteBits,
+		formatFloat(r.MeanFitness), strconv.Itoa(r.MinFitness), strconv.Itoa(r.MaxFitness),
+		formatFloat(r.StdDevFitness), formatFloat(r.Diversity),
+		strconv.Itoa(r.Evaluations), r.Started.Format(time.RFC3339Nano),
+		strconv.FormatInt(int64(r.Elapsed), 10), strconv.FormatInt(int64(r.Duration), 10),
+	}
+}
+
+// RunLogger - an EliteConsumer that writes a GenerationRecord for every generation
+// * Err - returns the first error writing a record, no more records are written after one
+// * Close - closes the file of a logger that opened one, and returns Err
+type RunLogger interface {
+	EliteConsumer
+	Err() error
+	Close() error
+}
+
+type runLogger struct {
+	ga          *GeneticAlgorithm
+	write       func(*GenerationRecord) error
+	file        *os.File
+	lastElapsed time.Duration
+	err         error
+	mutex       sync.Mutex
+}
+
+// NewJSONLinesLogger returns a RunLogger that writes a JSON object for each
+// generation of 'ga' to 'w', one per line. Set it as the EliteConsumer of 'ga',
+// see NewMultiEliteConsumer to use it alongside another
+func NewJSONLinesLogger(w io.Writer, ga *GeneticAlgorithm) RunLogger {
+	return &runLogger{
+		ga: ga,
+		write: func(record *GenerationRecord) error {
+			line, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(append(line, '\n'))
+			return err
+		},
+	}
+}
+
+// NewCSVLogger returns a RunLogger that writes a header followed by a CSV row for
+// each generation of 'ga' to 'w'. Set it as the EliteConsumer of 'ga', see
+// NewMultiEliteConsumer to use it alongside another
+func NewCSVLogger(w io.Writer, ga *GeneticAlgorithm) RunLogger {
+	return newCSVLogger(w, ga, true)
+}
+
+func newCSVLogger(w io.Writer, ga *GeneticAlgorithm, header bool) *runLogger {
+	writer := csv.NewWriter(w)
+	return &runLogger{
+		ga: ga,
+		write: func(record *GenerationRecord) error {
+			if header {
+				header = false
+				writer.Write(csvColumns)
+			}
+			writer.Write(record.csvRow())
+			writer.Flush()
+			return writer.Error()
+		},
+	}
+}
+
+// openLogFile opens the file at 'path' for appending, creating it if it does
+// not exist, and returns true if it is empty
+func openLogFile(path string) (*os.File, bool, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, false, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, false, err
+	}
+	return file, info.Size() == 0, nil
+}
+
+// NewJSONLinesFileLogger returns a RunLogger that appends the records of 'ga' to the
+// file at 'path' as NewJSONLinesLogger does, creating the file if it does not exist
+func NewJSONLinesFileLogger(path string, ga *GeneticAlgorithm) (RunLogger, error) {
+	file, _, err := openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ret := NewJSONLinesLogger(file, ga).(*runLogger)
+	ret.file = file
+	return ret, nil
+}
+
+// NewCSVFileLogger returns a RunLogger that appends the records of 'ga' to the file at
+// 'path' as NewCSVLogger does, the header is only written if the file is new or empty
+func NewCSVFileLogger(path string, ga *GeneticAlgorithm) (RunLogger, error) {
+	file, empty, err := openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ret := newCSVLogger(file, ga, empty)
+	ret.file = file
+	return ret, nil
+}
+
+// OnElite writes the record of the generation 'elite' is the elite of, it is
+// called on the goroutine that called Simulate so the population is not changing
+func (rl *runLogger) OnElite(elite Genome) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	if rl.err != nil {
+		return
+	}
+	record := rl.record(elite)
+	rl.err = rl.write(&record)
+}
+
+func (rl *runLogger) record(elite Genome) GenerationRecord {
+	state := rl.ga.GetRunState()
+	population := rl.ga.GetPopulation()
+	if state.Generation <= 1 {
+		rl.lastElapsed = 0
+	}
+
+	ret := GenerationRecord{
+		Generation:   state.Generation,
+		EliteFitness: elite.GetFitness(),
+		EliteBits:    elite.GetBits().Hex(),
+		Diversity:    Diversity(population),
+		Evaluations:  state.Evaluations,
+		Started:      state.StartTime,
+		Elapsed:      state.Elapsed,
+		Duration:     state.Elapsed - rl.lastElapsed,
+	}
+	ret.MeanFitness, ret.MinFitness, ret.MaxFitness, ret.StdDevFitness = fitnessStats(population)
+	rl.lastElapsed = state.Elapsed
+	return ret
+}
+
+func (rl *runLogger) Err() error {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	return rl.err
+}
+
+func (rl *runLogger) Close() error {
+	err := rl.Err()
+	if rl.file != nil {
+		if closeErr := rl.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// fitnessStats returns the mean, min, max and standard deviation of the fitness of 'population'
+func fitnessStats(population []Genome) (mean float64, minFitness, maxFitness int, stdDev float64) {
+	if len(population) == 0 {
+		return 0, 0, 0, 0
+	}
+
+	minFitness, maxFitness = population[0].GetFitness(), population[0].GetFitness()
+	total := 0.0
+	for _, g := range population {
+		minFitness = min(minFitness, g.GetFitness())
+		maxFitness = max(maxFitness, g.GetFitness())
+		total += float64(g.GetFitness())
+	}
+	mean = total / float64(len(population))
+
+	variance := 0.0
+	for _, g := range population {
+		variance += (float64(g.GetFitness()) - mean) * (float64(g.GetFitness()) - mean)
+	}
+	stdDev = math.Sqrt(variance / float64(len(population)))
+	return mean, minFitness, maxFitness, stdDev
+}
+
+// ReadJSONLinesLog reads the records written by a JSON lines logger from 'r',
+// blank lines are ignored
+func ReadJSONLinesLog(r io.Reader) ([]GenerationRecord, error) {
+	var ret []GenerationRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024*64)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var record GenerationRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("line %v: %v", lineNumber, err)
+		}
+		ret = append(ret, record)
+	}
+	return ret, scanner.Err()
+}
+
+// ReadCSVLog reads the records written by a CSV logger from 'r'. Columns are
+// matched by the names in the header, so they can be in any order and missing
+// columns are left zero'd. Repeated headers are skipped
+func ReadCSVLog(r io.Reader) ([]GenerationRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[name] = i
+	}
+
+	var ret []GenerationRecord
+	for lineNumber := 2; ; lineNumber++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return ret, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(row) > 0 && row[0] == header[0] {
+			continue
+		}
+
+		record, err := parseCSVRow(row, columns)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", lineNumber, err)
+		}
+		ret = append(ret, record)
+	}
+}
+
+func parseCSVRow(row []string, columns map[string]int) (GenerationRecord, error) {
+	var ret GenerationRecord
+	var err error
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	parseInt := func(name string, value *int) {
+		if s := field(name); s != "" && err == nil {
+			*value, err = strconv.Atoi(s)
+		}
+	}
+	parseFloat := func(name string, value *float64) {
+		if s := field(name); s != "" && err == nil {
+			*value, err = strconv.ParseFloat(s, 64)
+		}
+	}
+	parseDuration := func(name string, value *time.Duration) {
+		if s := field(name); s != "" && err == nil {
+			var ns int64
+			ns, err = strconv.ParseInt(s, 10, 64)
+			*value = time.Duration(ns)
+		}
+	}
+
+	parseInt("generation", &ret.Generation)
+	parseInt("eliteFitness", &ret.EliteFitness)
+	ret.EliteBits = field("eliteBits")
+	parseFloat("meanFitness", &ret.MeanFitness)
+	parseInt("minFitness", &ret.MinFitness)
+	parseInt("maxFitness", &ret.MaxFitness)
+	parseFloat("stdDevFitness", &ret.StdDevFitness)
+	parseFloat("diversity", &ret.Diversity)
+	parseInt("evaluations", &ret.Evaluations)
+	if s := field("started"); s != "" && err == nil {
+		ret.Started, err = time.Parse(time.RFC3339Nano, s)
+	}
+	parseDuration("elapsedNs", &ret.Elapsed)
+	parseDuration("durationNs", &ret.Duration)
+	return ret, err
+}
+
+// ReadLogFile reads the records in the log file at 'path', which can have been
+// written by either a JSON lines or a CSV logger
+func ReadLogFile(path string) ([]GenerationRecord, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")) {
+		return ReadJSONLinesLog(bytes.NewReader(content))
+	}
+	return ReadCSVLog(bytes.NewReader(content))
+}
@@ -0,0 +1,172 @@
+package goga_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type RunLogSuite struct {
+}
+
+var _ = Suite(&RunLogSuite{})
+
+func helperLoggedRun(t *C, consumer func(*goga.GeneticAlgorithm) goga.EliteConsumer, generations int) *goga.GeneticAlgorithm {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorCountOnes{}
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(16)
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.UniformCrossover},
+		{P: 1, F: goga.Mutate},
+	})
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: goga.Roulette},
+	})
+	genAlgo.Terminator = goga.MaxGenerations(generations)
+	genAlgo.EliteConsumer = consumer(&genAlgo)
+	genAlgo.Init(10, 2)
+	genAlgo.Simulate()
+	return &genAlgo
+}
+
+// helperCheckRecords checks 'records' describe a run of 'genAlgo'
+func helperCheckRecords(t *C, records []goga.GenerationRecord, genAlgo *goga.GeneticAlgorithm) {
+	state := genAlgo.GetRunState()
+	t.Assert(records, HasLen, state.Generation)
+	for i, record := range records {
+		t.Assert(record.Generation, Equals, i+1)
+		t.Assert(record.EliteFitness, Equals, state.EliteHistory[i])
+		t.Assert(record.Evaluations, Equals, (i+1)*10)
+		t.Assert(record.MaxFitness, Equals, record.EliteFitness)
+		t.Assert(record.MinFitness <= int(record.MeanFitness), IsTrue)
+		t.Assert(record.Started.Equal(state.StartTime), IsTrue)
+		t.Assert(record.Duration >= 0, IsTrue)
+
+		bits, err := record.EliteBitset()
+		t.Assert(err, IsNil)
+		t.Assert(helperCountOnes(&bits), Equals, record.EliteFitness)
+	}
+
+	last := records[len(records)-1]
+	t.Assert(last.Elapsed, Equals, state.Elapsed)
+	t.Assert(last.EliteBits, Equals, state.Elite.GetBits().Hex())
+	t.Assert(last.Diversity, Equals, goga.Diversity(genAlgo.GetPopulation()))
+}
+
+func (s *RunLogSuite) TestShouldLogJSONLines(t *C) {
+	var buffer bytes.Buffer
+	var logger goga.RunLogger
+	genAlgo := helperLoggedRun(t, func(ga *goga.GeneticAlgorithm) goga.EliteConsumer {
+		logger = goga.NewJSONLinesLogger(&buffer, ga)
+		return logger
+	}, 5)
+	t.Assert(logger.Close(), IsNil)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	t.Assert(lines, HasLen, 5)
+	t.Assert(strings.HasPrefix(lines[0], `{"generation":1,"eliteFitness":`), IsTrue)
+
+	records, err := goga.ReadJSONLinesLog(&buffer)
+	t.Assert(err, IsNil)
+	helperCheckRecords(t, records, genAlgo)
+}
+
+func (s *RunLogSuite) TestShouldLogCSV(t *C) {
+	var buffer bytes.Buffer
+	genAlgo := helperLoggedRun(t, func(ga *goga.GeneticAlgorithm) goga.EliteConsumer {
+		return goga.NewCSVLogger(&buffer, ga)
+	}, 5)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	t.Assert(lines, HasLen, 6)
+	t.Assert(lines[0], Equals, "generation,eliteFitness,eliteBits,meanFitness,minFitness,maxFitness,"+
+		"stdDevFitness,diversity,evaluations,started,elapsedNs,durationNs")
+
+	records, err := goga.ReadCSVLog(&buffer)
+	t.Assert(err, IsNil)
+	helperCheckRecords(t, records, genAlgo)
+}
+
+func (s *RunLogSuite) TestShouldReadCSVColumnsByName(t *C) {
+	records, err := goga.ReadCSVLog(strings.NewReader("eliteBits,generation\n0x5,1\neliteBits,generation\n0xf,2\n"))
+	t.Assert(err, IsNil)
+	t.Assert(records, DeepEquals, []goga.GenerationRecord{
+		{Generation: 1, EliteBits: "0x5"},
+		{Generation: 2, EliteBits: "0xf"},
+	})
+
+	_, err = goga.ReadCSVLog(strings.NewReader("generation\none\n"))
+	t.Assert(err, ErrorMatches, "line 2: .*")
+	_, err = goga.ReadJSONLinesLog(strings.NewReader("{\"generation\":1}\n\nnot json\n"))
+	t.Assert(err, ErrorMatches, "line 3: .*")
+}
+
+func (s *RunLogSuite) TestShouldAppendToLogFiles(t *C) {
+	dir := t.MkDir()
+	for _, newLogger := range []func(string, *goga.GeneticAlgorithm) (goga.RunLogger, error){
+		goga.NewJSONLinesFileLogger,
+		goga.NewCSVFileLogger,
+	} {
+		path := filepath.Join(dir, "run.log")
+		os.Remove(path)
+
+		var genAlgos []*goga.GeneticAlgorithm
+		for run := 0; run < 2; run++ {
+			var logger goga.RunLogger
+			genAlgos = append(genAlgos, helperLoggedRun(t, func(ga *goga.GeneticAlgorithm) goga.EliteConsumer {
+				var err error
+				logger, err = newLogger(path, ga)
+				t.Assert(err, IsNil)
+				return logger
+			}, 3))
+			t.Assert(logger.Close(), IsNil)
+		}
+
+		// Both runs are in the file, after a single header for CSV
+		records, err := goga.ReadLogFile(path)
+		t.Assert(err, IsNil)
+		helperCheckRecords(t, records[:3], genAlgos[0])
+		helperCheckRecords(t, records[3:], genAlgos[1])
+	}
+
+	_, err := goga.NewCSVFileLogger(filepath.Join(dir, "missing", "run.log"), nil)
+	t.Assert(err, NotNil)
+}
+
+type MyFailingWriter struct {
+	NumCalls int
+}
+
+func (w *MyFailingWriter) Write([]byte) (int, error) {
+	w.NumCalls++
+	return 0, errors.New("disk full")
+}
+
+func (s *RunLogSuite) TestShouldStopLoggingAfterAnError(t *C) {
+	writer := &MyFailingWriter{}
+	var logger goga.RunLogger
+	helperLoggedRun(t, func(ga *goga.GeneticAlgorithm) goga.EliteConsumer {
+		logger = goga.NewJSONLinesLogger(writer, ga)
+		return logger
+	}, 3)
+
+	t.Assert(writer.NumCalls, Equals, 1)
+	t.Assert(logger.Err(), ErrorMatches, "disk full")
+	t.Assert(logger.Close(), ErrorMatches, "disk full")
+}
+
+func (s *RunLogSuite) TestShouldPassEliteToEveryConsumer(t *C) {
+	var buffer bytes.Buffer
+	counter := &MyEliteConsumerCounter{}
+	helperLoggedRun(t, func(ga *goga.GeneticAlgorithm) goga.EliteConsumer {
+		return goga.NewMultiEliteConsumer(counter, goga.NewCSVLogger(&buffer, ga))
+	}, 4)
+
+	t.Assert(counter.NumCalls, Equals, 4)
+	t.Assert(strings.Count(buffer.String(), "\n"), Equals, 5)
+}
@@ -0,0 +1,448 @@
+package goga
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Schema - describes how a Go struct is stored in a bitset. It is built from
+// `goga` struct tags by NewSchema, for example -
+//
+//	type shape struct {
+//		X      int       `goga:"bits=9,min=0,max=511"`
+//		Scale  float64   `goga:"float,min=-1,max=1,bits=16"`
+//		Filled bool      `goga:"bool"`
+//		Kind   string    `goga:"enum=rect|circle"`
+//		Colour [3]uint8  `goga:"bits=8"`
+//		Centre struct {
+//			X, Y int `goga:"bits=4,gray"`
+//		}
+//	}
+//
+// The tag options are -
+// * bits=N - the number of bits used by the value
+// * min=N, max=N - the range of a value, integers are stored as an offset from min
+// that wraps around at max, floats are scaled evenly between min and max
+// * float - a float scaled between min and max, the default for floats
+// * fixed,frac=N - a fixed point float with N bits after the binary point
+// * gray - an unsigned integer, or integer with a min, stored as a Gray code
+// * bool - a bool stored in a single bit
+// * enum=a|b|c - a string that is one of the listed values
+// * - - the field is ignored
+//
+// Integers without a min are stored as two's complement if they are signed.
+// Fields without a tag are ignored unless they are structs, or arrays of structs,
+// which are always included. Arrays use their tag for every element.
+type Schema interface {
+	GetSize() int
+	GetBitsetParse() BitsetParse
+	GetBitsetCreate() BitsetCreate
+	Decode(*Bitset, interface{}) error
+	Encode(interface{}) (Bitset, error)
+}
+
+type schemaLeaf struct {
+	name   string
+	path   []int
+	field  Field
+	hasMin bool
+	min    int64
+	span   uint64
+}
+
+type schema struct {
+	structType reflect.Type
+	leaves     []schemaLeaf
+	parse      BitsetParse
+	size       int
+}
+
+// NewSchema returns a Schema for the type of struct 'v', which may be
+// a struct or a pointer to one
+func NewSchema(v interface{}) (Schema, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct but got %v", t)
+	}
+
+	s := &schema{structType: t}
+	if err := s.addStruct(t, nil, ""); err != nil {
+		return nil, err
+	}
+
+	fields := make([]Field, len(s.leaves))
+	for i, leaf := range s.leaves {
+		fields[i] = leaf.field
+		s.size += leaf.field.Bits
+	}
+	s.parse = CreateBitsetParse()
+	if err := s.parse.SetFields(fields); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *schema) addStruct(t reflect.Type, path []int, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		tag, tagged := structField.Tag.Lookup("goga")
+		if tag == "-" || structField.PkgPath != "" {
+			continue
+		}
+
+		fieldPath := append(append([]int{}, path...), i)
+		name := prefix + structField.Name
+		if err := s.addValue(structField.Type, fieldPath, name, tag, tagged); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *schema) addValue(t reflect.Type, path []int, name, tag string, tagged bool) error {
+	switch t.Kind() {
+	case reflect.Struct:
+		return s.addStruct(t, path, name+".")
+	case reflect.Array:
+		for i := 0; i < t.Len(); i++ {
+			elementPath := append(append([]int{}, path...), i)
+			elementName := fmt.Sprintf("%v[%v]", name, i)
+			if err := s.addValue(t.Elem(), elementPath, elementName, tag, tagged); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !tagged {
+		return nil
+	}
+
+	leaf, err := parseSchemaTag(t, tag)
+	if err != nil {
+		return fmt.Errorf("field %v: %v", name, err)
+	}
+	leaf.name = name
+	leaf.path = path
+	s.leaves = append(s.leaves, leaf)
+	return nil
+}
+
+func parseSchemaTag(t reflect.Type, tag string) (schemaLeaf, error) {
+	options := map[string]string{}
+	for _, option := range strings.Split(tag, ",") {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+		keyValue := strings.SplitN(option, "=", 2)
+		if len(keyValue) == 2 {
+			options[keyValue[0]] = keyValue[1]
+		} else {
+			options[keyValue[0]] = ""
+		}
+	}
+
+	leaf := schemaLeaf{}
+	parseInt := func(key string) (int64, bool, error) {
+		value, ok := options[key]
+		if !ok {
+			return 0, false, nil
+		}
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid %v %q", key, value)
+		}
+		return i, true, nil
+	}
+	parseFloat := func(key string) (float64, bool, error) {
+		value, ok := options[key]
+		if !ok {
+			return 0, false, nil
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid %v %q", key, value)
+		}
+		return f, true, nil
+	}
+
+	numBits, hasBits, err := parseInt("bits")
+	if err != nil {
+		return leaf, err
+	}
+	leaf.field.Bits = int(numBits)
+	_, gray := options["gray"]
+
+	switch t.Kind() {
+	case reflect.Bool:
+		leaf.field.Encoding = EncodingBool
+		if !hasBits {
+			leaf.field.Bits = 1
+		}
+
+	case reflect.String:
+		values, ok := options["enum"]
+		if !ok {
+			return leaf, fmt.Errorf("strings must be an enum")
+		}
+		for _, value := range strings.Split(values, "|") {
+			leaf.field.Values = append(leaf.field.Values, value)
+		}
+		leaf.field.Encoding = EncodingEnum
+		if !hasBits {
+			leaf.field.Bits = bits.Len(uint(len(leaf.field.Values) - 1))
+			if leaf.field.Bits == 0 {
+				leaf.field.Bits = 1
+			}
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if _, fixed := options["fixed"]; fixed {
+			frac, _, err := parseInt("frac")
+			if err != nil {
+				return leaf, err
+			}
+			leaf.field.Encoding = EncodingFixedPoint
+			leaf.field.FractionBits = int(frac)
+			break
+		}
+
+		leaf.field.Encoding = EncodingScaledFloat
+		min, hasMin, err := parseFloat("min")
+		if err != nil {
+			return leaf, err
+		}
+		max, hasMax, err := parseFloat("max")
+		if err != nil {
+			return leaf, err
+		}
+		if !hasMin || !hasMax {
+			return leaf, fmt.Errorf("floats need a min and max, or to be fixed point")
+		}
+		leaf.field.Min, leaf.field.Max = min, max
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		min, hasMin, err := parseInt("min")
+		if err != nil {
+			return leaf, err
+		}
+		max, hasMax, err := parseInt("max")
+		if err != nil {
+			return leaf, err
+		}
+		if hasMax && !hasMin {
+			return leaf, fmt.Errorf("max needs a min")
+		}
+		if hasMax && max < min {
+			return leaf, fmt.Errorf("max is less than min")
+		}
+		leaf.hasMin, leaf.min = hasMin, min
+		if hasMax {
+			leaf.span = uint64(max-min) + 1
+		}
+
+		signed := t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64
+		switch {
+		case gray:
+			leaf.field.Encoding = EncodingGray
+		case signed && !hasMin:
+			leaf.field.Encoding = EncodingSigned
+		default:
+			leaf.field.Encoding = EncodingUnsigned
+		}
+
+		if !hasBits {
+			if !hasMax {
+				return leaf, fmt.Errorf("integers need bits or a min and max")
+			}
+			leaf.field.Bits = bits.Len64(leaf.span - 1)
+			if leaf.field.Bits == 0 {
+				leaf.field.Bits = 1
+			}
+		}
+
+	default:
+		return leaf, fmt.Errorf("unsupported type %v", t)
+	}
+
+	if leaf.field.Bits <= 0 || leaf.field.Bits > 64 {
+		return leaf, fmt.Errorf("bits must be between 1 and 64")
+	}
+	return leaf, nil
+}
+
+func (s *schema) GetSize() int {
+	return s.size
+}
+
+func (s *schema) GetBitsetParse() BitsetParse {
+	return s.parse
+}
+
+func (s *schema) GetBitsetCreate() BitsetCreate {
+	return NewRandomBitsetCreate(s.size)
+}
+
+func (s *schema) structValue(v interface{}) (reflect.Value, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr && !value.IsNil() && value.Type().Elem().Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Type() != s.structType {
+		return reflect.Value{}, fmt.Errorf("expected a pointer to %v but got %T", s.structType, v)
+	}
+	return value.Elem(), nil
+}
+
+func leafValue(v reflect.Value, path []int) reflect.Value {
+	for _, i := range path {
+		if v.Kind() == reflect.Struct {
+			v = v.Field(i)
+		} else {
+			v = v.Index(i)
+		}
+	}
+	return v
+}
+
+// Decode sets the fields of the struct pointed to by 'v' from 'bitset'
+func (s *schema) Decode(bitset *Bitset, v interface{}) error {
+	value, err := s.structValue(v)
+	if err != nil {
+		return err
+	}
+	values, err := s.parse.ProcessFieldsE(bitset)
+	if err != nil {
+		return err
+	}
+	for i, leaf := range s.leaves {
+		if err := leaf.set(leafValue(value, leaf.path), values[i]); err != nil {
+			return fmt.Errorf("field %v: %v", leaf.name, err)
+		}
+	}
+	return nil
+}
+
+func (leaf *schemaLeaf) set(v reflect.Value, decoded interface{}) error {
+	switch d := decoded.(type) {
+	case bool:
+		v.SetBool(d)
+		return nil
+	case string:
+		v.SetString(d)
+		return nil
+	case float64:
+		v.SetFloat(d)
+		return nil
+	case int64:
+		if v.OverflowInt(d) {
+			return fmt.Errorf("%v overflows %v", d, v.Type())
+		}
+		v.SetInt(d)
+		return nil
+	}
+
+	raw := decoded.(uint64)
+	if leaf.span != 0 {
+		raw %= leaf.span
+	}
+
+	if !leaf.hasMin {
+		if v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uint64 {
+			if v.OverflowUint(raw) {
+				return fmt.Errorf("%v overflows %v", raw, v.Type())
+			}
+			v.SetUint(raw)
+			return nil
+		}
+		if raw > math.MaxInt64 || v.OverflowInt(int64(raw)) {
+			return fmt.Errorf("%v overflows %v", raw, v.Type())
+		}
+		v.SetInt(int64(raw))
+		return nil
+	}
+
+	result := leaf.min + int64(raw)
+	if v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uint64 {
+		if result < 0 || v.OverflowUint(uint64(result)) {
+			return fmt.Errorf("%v overflows %v", result, v.Type())
+		}
+		v.SetUint(uint64(result))
+		return nil
+	}
+	if v.OverflowInt(result) {
+		return fmt.Errorf("%v overflows %v", result, v.Type())
+	}
+	v.SetInt(result)
+	return nil
+}
+
+// Encode creates a bitset from the struct, or pointer to struct, 'v'
+func (s *schema) Encode(v interface{}) (Bitset, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if !value.IsValid() || value.Type() != s.structType {
+		return Bitset{}, fmt.Errorf("expected %v but got %T", s.structType, v)
+	}
+
+	values := make([]interface{}, len(s.leaves))
+	for i, leaf := range s.leaves {
+		encoded, err := leaf.get(leafValue(value, leaf.path))
+		if err != nil {
+			return Bitset{}, fmt.Errorf("field %v: %v", leaf.name, err)
+		}
+		values[i] = encoded
+	}
+
+	ret, err := s.parse.Encode(values)
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) && fieldErr.Index < len(s.leaves) {
+		// Replace the field index with its name
+		return Bitset{}, fmt.Errorf("field %v: %v", s.leaves[fieldErr.Index].name, fieldErr.Err)
+	}
+	return ret, err
+}
+
+func (leaf *schemaLeaf) get(v reflect.Value) (interface{}, error) {
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	}
+
+	var i int64
+	if v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uint64 {
+		if !leaf.hasMin {
+			return v.Uint(), nil
+		}
+		if v.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("%v is too large", v.Uint())
+		}
+		i = int64(v.Uint())
+	} else {
+		i = v.Int()
+		if !leaf.hasMin {
+			return i, nil
+		}
+	}
+
+	if i < leaf.min || (leaf.span != 0 && uint64(i-leaf.min) >= leaf.span) {
+		return nil, fmt.Errorf("%v is outside of the field's range", i)
+	}
+	return uint64(i - leaf.min), nil
+}
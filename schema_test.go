@@ -0,0 +1,174 @@
+package goga_test
+
+import (
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type SchemaSuite struct {
+}
+
+var _ = Suite(&SchemaSuite{})
+
+type schemaPoint struct {
+	X int `goga:"bits=4,gray"`
+	Y int `goga:"bits=4"`
+}
+
+type schemaShape struct {
+	Position int      `goga:"bits=9,min=0,max=511"`
+	Offset   int      `goga:"min=-3,max=4"`
+	Scale    float64  `goga:"float,min=-1,max=1,bits=16"`
+	Angle    float32  `goga:"fixed,bits=8,frac=2"`
+	Filled   bool     `goga:"bool"`
+	Kind     string   `goga:"enum=rect|circle|triangle"`
+	Colour   [3]uint8 `goga:"bits=8"`
+	Centre   schemaPoint
+	Corners  [2]schemaPoint
+	Ignored  int `goga:"-"`
+	Untagged int
+}
+
+func (s *SchemaSuite) TestShouldCalculateSize(t *C) {
+	schema, err := goga.NewSchema(schemaShape{})
+	t.Assert(err, IsNil)
+
+	// 9 + 3 + 16 + 8 + 1 + 2 + (3 * 8) + 8 + (2 * 8)
+	t.Assert(schema.GetSize(), Equals, 87)
+
+	b := schema.GetBitsetCreate().Go()
+	t.Assert(b.GetSize(), Equals, 87)
+}
+
+func (s *SchemaSuite) TestShouldAcceptPointer(t *C) {
+	schema, err := goga.NewSchema(&schemaPoint{})
+	t.Assert(err, IsNil)
+	t.Assert(schema.GetSize(), Equals, 8)
+}
+
+func (s *SchemaSuite) TestShouldDecode(t *C) {
+	schema, err := goga.NewSchema(schemaPoint{})
+	t.Assert(err, IsNil)
+
+	// X is the Gray code 0011 (2), Y is 1111 as two's complement (-1)
+	point := schemaPoint{}
+	t.Assert(schema.Decode(helperBitset(t, "11001111"), &point), IsNil)
+	t.Assert(point, Equals, schemaPoint{X: 2, Y: -1})
+}
+
+func (s *SchemaSuite) TestShouldWrapIntegersWithARange(t *C) {
+	type wrapped struct {
+		Value int `goga:"bits=3,min=10,max=14"`
+	}
+	schema, err := goga.NewSchema(wrapped{})
+	t.Assert(err, IsNil)
+
+	w := wrapped{}
+	t.Assert(schema.Decode(helperBitset(t, "011"), &w), IsNil)
+	t.Assert(w.Value, Equals, 16%5+10)
+}
+
+func (s *SchemaSuite) TestShouldRoundTripStructs(t *C) {
+	schema, err := goga.NewSchema(schemaShape{})
+	t.Assert(err, IsNil)
+
+	shape := schemaShape{
+		Position: 300,
+		Offset:   -2,
+		Scale:    1,
+		Angle:    -1.75,
+		Filled:   true,
+		Kind:     "triangle",
+		Colour:   [3]uint8{255, 0, 128},
+		Centre:   schemaPoint{X: 15, Y: -8},
+		Corners:  [2]schemaPoint{{X: 1, Y: 2}, {X: 3, Y: 7}},
+		Ignored:  5,
+		Untagged: 6,
+	}
+	b, err := schema.Encode(&shape)
+	t.Assert(err, IsNil)
+	t.Assert(b.GetSize(), Equals, schema.GetSize())
+
+	decoded := schemaShape{}
+	t.Assert(schema.Decode(&b, &decoded), IsNil)
+
+	shape.Ignored, shape.Untagged = 0, 0
+	t.Assert(decoded, DeepEquals, shape)
+}
+
+func (s *SchemaSuite) TestShouldRoundTripRandomBitsets(t *C) {
+	schema, err := goga.NewSchema(schemaPoint{})
+	t.Assert(err, IsNil)
+
+	create := schema.GetBitsetCreate()
+	for i := 0; i < 100; i++ {
+		b := create.Go()
+
+		point := schemaPoint{}
+		t.Assert(schema.Decode(&b, &point), IsNil)
+
+		encoded, err := schema.Encode(point)
+		t.Assert(err, IsNil)
+		t.Assert(encoded.GetAll(), DeepEquals, b.GetAll())
+	}
+}
+
+func (s *SchemaSuite) TestShouldProduceMatchingBitsetParse(t *C) {
+	schema, err := goga.NewSchema(schemaPoint{})
+	t.Assert(err, IsNil)
+
+	values := schema.GetBitsetParse().ProcessFields(helperBitset(t, "11001111"))
+	t.Assert(values, DeepEquals, []interface{}{uint64(2), int64(-1)})
+}
+
+func (s *SchemaSuite) TestShouldNotEncodeOutOfRangeValues(t *C) {
+	schema, err := goga.NewSchema(schemaShape{})
+	t.Assert(err, IsNil)
+
+	_, err = schema.Encode(schemaShape{Position: 512, Kind: "rect"})
+	t.Assert(err, ErrorMatches, "field Position: .*")
+
+	_, err = schema.Encode(schemaShape{Kind: "square"})
+	t.Assert(err, ErrorMatches, "field Kind: .*")
+
+	_, err = schema.Encode(schemaShape{Kind: "rect", Centre: schemaPoint{Y: 8}})
+	t.Assert(err, ErrorMatches, "field Centre.Y: .*")
+}
+
+func (s *SchemaSuite) TestShouldNotDecodeIntoTheWrongType(t *C) {
+	schema, err := goga.NewSchema(schemaPoint{})
+	t.Assert(err, IsNil)
+
+	b := schema.GetBitsetCreate().Go()
+	t.Assert(schema.Decode(&b, schemaPoint{}), NotNil)
+	t.Assert(schema.Decode(&b, &schemaShape{}), NotNil)
+
+	wrongSize := goga.Bitset{}
+	wrongSize.Create(3)
+	t.Assert(schema.Decode(&wrongSize, &schemaPoint{}), NotNil)
+}
+
+func (s *SchemaSuite) TestShouldRejectInvalidTags(t *C) {
+	_, err := goga.NewSchema(struct {
+		Value int `goga:"min=0"`
+	}{})
+	t.Assert(err, ErrorMatches, "field Value: integers need bits or a min and max")
+
+	_, err = goga.NewSchema(struct {
+		Value float64 `goga:"bits=8"`
+	}{})
+	t.Assert(err, ErrorMatches, "field Value: floats need a min and max, or to be fixed point")
+
+	_, err = goga.NewSchema(struct {
+		Value int `goga:"bits=65"`
+	}{})
+	t.Assert(err, ErrorMatches, "field Value: bits must be between 1 and 64")
+
+	_, err = goga.NewSchema(struct {
+		Value []int `goga:"bits=1"`
+	}{})
+	t.Assert(err, ErrorMatches, "field Value: unsupported type .*")
+
+	_, err = goga.NewSchema(5)
+	t.Assert(err, NotNil)
+}
@@ -0,0 +1,138 @@
+package goga
+
+import (
+	"math"
+	"math/rand"
+)
+
+const defaultMaxMutationRate = 0.5
+
+// MutationRateGenome - a genome that carries its own mutation rate alongside its
+// bitset, the rate is a strategy parameter that evolves with the population
+// rather than being fixed for the whole run, see NewSelfAdaptiveMater
+type MutationRateGenome interface {
+	Genome
+	GetMutationRate() float64
+}
+
+type selfAdaptiveGenome struct {
+	genome
+	mutationRate float64
+}
+
+// NewSelfAdaptiveGenome creates a genome with a bitset, a mutation rate
+// and a zero'd fitness score
+func NewSelfAdaptiveGenome(bitset Bitset, mutationRate float64) MutationRateGenome {
+	return &selfAdaptiveGenome{
+		genome:       genome{bitset: bitset},
+		mutationRate: mutationRate,
+	}
+}
+
+func (g *selfAdaptiveGenome) GetMutationRate() float64 {
+	return g.mutationRate
+}
+
+// Copy returns a deep copy of the genome, keeping its mutation rate
+func (g *selfAdaptiveGenome) Copy() Genome {
+	return NewSelfAdaptiveGenome(g.bitset.CreateCopy(), g.mutationRate)
+}
+
+// Key returns the genome's bits, genomes that only differ in their
+// mutation rate are the same solution
+func (g *selfAdaptiveGenome) Key() string {
+	return g.bitset.String()
+}
+
+// SelfAdaptiveConfig -
+// Configures how NewSelfAdaptiveMater evolves mutation rates
+// * InitialRate - the mutation rate of parents that do not carry one, such as the initial population
+// * LearningRate - the standard deviation of the log-normal perturbation of the rate,
+// 1 / the square root of the number of bits if not set
+// * MinRate - the lowest a rate can become, 1 / the number of bits if not set
+// * MaxRate - the highest a rate can become, 0.5 if not set
+type SelfAdaptiveConfig struct {
+	InitialRate  float64
+	LearningRate float64
+	MinRate      float64
+	MaxRate      float64
+}
+
+type selfAdaptiveMater struct {
+	mater  Mater
+	config SelfAdaptiveConfig
+}
+
+// NewSelfAdaptiveMater returns a Mater that breeds offspring with 'mater' and then
+// mutates them with a rate each offspring carries in its genome.
+// Each offspring inherits the mean of its parents' rates, which is perturbed by
+// multiplying it by exp(LearningRate * N(0, 1)) before every bit of the offspring
+// is flipped with the new rate. Rates that breed fitter offspring are more likely
+// to survive so the rate adapts to the problem as it is solved.
+// The offspring are MutationRateGenomes, so 'mater' should only use the bits of
+// its genomes, e.g. a mater made by NewMater with the crossover functions
+func NewSelfAdaptiveMater(mater Mater, config SelfAdaptiveConfig) Mater {
+	return &selfAdaptiveMater{
+		mater:  mater,
+		config: config,
+	}
+}
+
+func (m *selfAdaptiveMater) mutationRate(g Genome) float64 {
+	if rateGenome, ok := g.(MutationRateGenome); ok {
+		return rateGenome.GetMutationRate()
+	}
+	return m.config.InitialRate
+}
+
+// Go breeds two offspring with the wrapped mater and mutates them with their own rates
+func (m *selfAdaptiveMater) Go(g1, g2 Genome) (Genome, Genome) {
+	rate := (m.mutationRate(g1) + m.mutationRate(g2)) / 2
+	c1, c2 := m.mater.Go(g1, g2)
+	record := recordsLineage(g1)
+	return m.mutate(c1, rate, record), m.mutate(c2, rate, record)
+}
+
+// mutate returns a copy of 'g' carrying a perturbed 'rate', with its bits mutated at that rate
+func (m *selfAdaptiveMater) mutate(g Genome, rate float64, record bool) Genome {
+	bits := g.GetBits().CreateCopy()
+	numBits := math.Max(1, float64(bits.GetSize()))
+
+	learningRate := m.config.LearningRate
+	if learningRate <= 0 {
+		learningRate = 1 / math.Sqrt(numBits)
+	}
+	minRate := m.config.MinRate
+	if minRate <= 0 {
+		minRate = 1 / numBits
+	}
+	maxRate := m.config.MaxRate
+	if maxRate <= 0 {
+		maxRate = defaultMaxMutationRate
+	}
+
+	rate *= math.Exp(learningRate * rand.NormFloat64())
+	rate = math.Min(maxRate, math.Max(minRate, rate))
+
+	flipBits(&bits, rate)
+	ret := NewSelfAdaptiveGenome(bits, rate)
+	if lineageGenome, ok := g.(LineageGenome); ok && record {
+		operators := lineageGenome.GetLineage().Operators
+		setOperators(ret, append(operators[:len(operators):len(operators)], "SelfAdaptiveMutation"))
+	}
+	return ret
+}
+
+// OnElite passes the elite on to the wrapped mater
+func (m *selfAdaptiveMater) OnElite(elite Genome) {
+	m.mater.OnElite(elite)
+}
+
+// flipBits flips each bit of 'bits' with probability 'rate'
+func flipBits(bits *Bitset, rate float64) {
+	for i := 0; i < bits.GetSize(); i++ {
+		if rand.Float64() < rate {
+			bits.Set(i, 1-bits.Get(i))
+		}
+	}
+}
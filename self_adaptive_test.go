@@ -0,0 +1,78 @@
+package goga_test
+
+import (
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type SelfAdaptiveSuite struct {
+}
+
+var _ = Suite(&SelfAdaptiveSuite{})
+
+func (s *SelfAdaptiveSuite) TestShouldCopyTheMutationRate(t *C) {
+	g := goga.NewSelfAdaptiveGenome(*helperBitset(t, "0110"), 0.25)
+	g.SetFitness(10)
+
+	c := goga.CopyGenome(g).(goga.MutationRateGenome)
+	t.Assert(c.GetMutationRate(), Equals, 0.25)
+	t.Assert(c.GetFitness(), Equals, 10)
+	t.Assert(c.GetBits().String(), Equals, "0110")
+
+	// Genomes are told apart by their bits alone
+	other := goga.NewSelfAdaptiveGenome(*helperBitset(t, "0110"), 0.5)
+	hallOfFame := goga.NewHallOfFame(2)
+	hallOfFame.Update([]goga.Genome{g, other})
+	t.Assert(hallOfFame.GetGenomes(), HasLen, 1)
+}
+
+func (s *SelfAdaptiveSuite) TestShouldMutateWithTheInheritedRate(t *C) {
+	mater := goga.NewSelfAdaptiveMater(&goga.NullMater{}, goga.SelfAdaptiveConfig{MinRate: 1, MaxRate: 1})
+
+	p1 := goga.NewSelfAdaptiveGenome(*helperBitset(t, "0011"), 1)
+	p2 := helperGenome(t, "0101")
+	c1, c2 := mater.Go(p1, p2)
+
+	// A rate of 1 flips every bit, and the parents are left alone
+	t.Assert(helperGenomeStrings([]goga.Genome{c1, c2}), DeepEquals, []string{"1100", "1010"})
+	t.Assert(helperGenomeStrings([]goga.Genome{p1, p2}), DeepEquals, []string{"0011", "0101"})
+	t.Assert(c1.(goga.MutationRateGenome).GetMutationRate(), Equals, 1.0)
+}
+
+func (s *SelfAdaptiveSuite) TestShouldPerturbRatesWithinBounds(t *C) {
+	config := goga.SelfAdaptiveConfig{InitialRate: 0.1, LearningRate: 0.5, MinRate: 0.01, MaxRate: 0.3}
+	mater := goga.NewSelfAdaptiveMater(goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 1, F: goga.UniformCrossover},
+	}), config)
+
+	rates := map[float64]bool{}
+	for i := 0; i < 100; i++ {
+		c1, c2 := mater.Go(helperGenome(t, "00000000"), helperGenome(t, "11111111"))
+		for _, c := range []goga.Genome{c1, c2} {
+			rate := c.(goga.MutationRateGenome).GetMutationRate()
+			t.Assert(rate >= 0.01 && rate <= 0.3, IsTrue)
+			rates[rate] = true
+		}
+	}
+	t.Assert(len(rates) > 100, IsTrue)
+}
+
+func (s *SelfAdaptiveSuite) TestShouldEvolveMutationRates(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Mater = goga.NewSelfAdaptiveMater(goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 0.8, F: goga.UniformCrossover},
+	}), goga.SelfAdaptiveConfig{InitialRate: 0.05})
+	genAlgo.Simulator = &MySimulatorCountOnes{}
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(32)
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: goga.Roulette},
+	})
+	genAlgo.Terminator = goga.MaxGenerations(5)
+	genAlgo.Init(20, 2)
+	genAlgo.Simulate()
+
+	for _, g := range genAlgo.GetPopulation() {
+		rate := g.(goga.MutationRateGenome).GetMutationRate()
+		t.Assert(rate >= 1.0/32 && rate <= 0.5, IsTrue)
+	}
+}
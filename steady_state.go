@@ -0,0 +1,160 @@
+package goga
+
+import (
+	"math/rand"
+)
+
+// ReplacementFunc -
+// Used in steady state mode to choose the index of the population member that
+// a newly simulated 'offspring' replaces. 'births' holds, for each member of the
+// population, the order it was added in so lower values are older.
+// Returning a negative index discards the offspring
+type ReplacementFunc func(population []Genome, births []int, offspring Genome) int
+
+// ReplaceWorst replaces the least fit member of the population if the offspring
+// is at least as fit, otherwise the offspring is discarded so the population never gets worse
+func ReplaceWorst(population []Genome, births []int, offspring Genome) int {
+	worst := 0
+	for i := range population {
+		if population[i].GetFitness() < population[worst].GetFitness() {
+			worst = i
+		}
+	}
+	if offspring.GetFitness() < population[worst].GetFitness() {
+		return -1
+	}
+	return worst
+}
+
+// ReplaceRandom replaces a random member of the population
+func ReplaceRandom(population []Genome, births []int, offspring Genome) int {
+	return rand.Intn(len(population))
+}
+
+// ReplaceOldest replaces the member of the population that has been in it the longest
+func ReplaceOldest(population []Genome, births []int, offspring Genome) int {
+	oldest := 0
+	for i := range births {
+		if births[i] < births[oldest] {
+			oldest = i
+		}
+	}
+	return oldest
+}
+
+// ReplaceTournamentLoser returns a ReplacementFunc that picks 'size' random
+// members of the population and replaces the least fit of them
+func ReplaceTournamentLoser(size int) ReplacementFunc {
+	return func(population []Genome, births []int, offspring Genome) int {
+		loser := rand.Intn(len(population))
+		for i := 1; i < size; i++ {
+			contender := rand.Intn(len(population))
+			if population[contender].GetFitness() < population[loser].GetFitness() {
+				loser = contender
+			}
+		}
+		return loser
+	}
+}
+
+// simulateSteadyState -
+// Rather than breeding a whole new population each generation, offspring are
+// bred one at a time and handed to the simulators as soon as one is free. When
+// an offspring has been simulated it replaces a member of the population chosen
+// by the Replacement function so no simulator is left waiting on the slowest
+// genome of a generation.
+// Every 'populationSize' simulations are treated as a generation: the simulator's
+// OnEndSimulation and OnBeginSimulation are called, and the elite is passed on and
+// checked as it would be in generational mode. Genomes still being simulated when
+// the algorithm exits are discarded.
+func (ga *GeneticAlgorithm) simulateSteadyState() {
+	replacement := ga.Replacement
+	if replacement == nil {
+		replacement = ReplaceWorst
+	}
+
+	if ga.onGeneration(ga.populationSize) {
+		return
+	}
+
+	numSimulators := ga.parallelSimulations
+	if numSimulators < 1 {
+		numSimulators = 1
+	}
+
+	results := make(chan Genome)
+	simulateFunc := ga.simulateFunc()
+	simulate := func(g Genome) {
+		ga.pool.submit(func() {
+			simulateFunc(g)
+			results <- g
+		})
+	}
+
+	births := make([]int, ga.populationSize)
+	for i := range births {
+		births[i] = i
+	}
+	numBirths := ga.populationSize
+
+	var bred []Genome
+	toCreate := 0
+	breed := func() Genome {
+		if toCreate == 0 {
+			toCreate = ga.takeToCreate()
+		}
+		if toCreate > 0 {
+			toCreate--
+			return ga.createGenome()
+		}
+
+		if len(bred) == 0 {
+			reproducer := ga.reproducer()
+			selected := selectParents(ga.Selector, ga.population, ga.totalFitness, reproducer.NumParents())
+			offspring := reproduce(reproducer, selected)
+			ga.prepareOffspring()(selected, offspring)
+			bred = append(bred, offspring...)
+		}
+		ret := bred[0]
+		bred = bred[1:]
+		return ret
+	}
+
+	ga.totalFitness = sumFitness(ga.population)
+	ga.Simulator.OnBeginSimulation()
+	inFlight := 0
+	for ; inFlight < numSimulators; inFlight++ {
+		simulate(breed())
+	}
+
+	numSimulated := 0
+	for {
+		offspring := <-results
+		inFlight--
+
+		index := replacement(ga.population, births, offspring)
+		if index >= 0 {
+			ga.population[index] = offspring
+			births[index] = numBirths
+			numBirths++
+			ga.totalFitness = sumFitness(ga.population)
+		}
+
+		numSimulated++
+		if numSimulated == ga.populationSize {
+			numSimulated = 0
+			ga.Simulator.OnEndSimulation()
+			if ga.onGeneration(ga.populationSize) {
+				break
+			}
+			ga.Simulator.OnBeginSimulation()
+		}
+
+		simulate(breed())
+		inFlight++
+	}
+
+	for ; inFlight > 0; inFlight-- {
+		<-results
+	}
+}
@@ -0,0 +1,150 @@
+package goga_test
+
+import (
+	"sync"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type SteadyStateSuite struct {
+}
+
+var _ = Suite(&SteadyStateSuite{})
+
+func helperPopulationWithFitnesses(fitnesses ...int) []goga.Genome {
+	ret := make([]goga.Genome, len(fitnesses))
+	for i, fitness := range fitnesses {
+		ret[i] = goga.NewGenome(goga.Bitset{})
+		ret[i].SetFitness(fitness)
+	}
+	return ret
+}
+
+func (s *SteadyStateSuite) TestShouldReplaceWorst(t *C) {
+	population := helperPopulationWithFitnesses(5, 2, 7, 3)
+	births := []int{0, 1, 2, 3}
+	t.Assert(goga.ReplaceWorst(population, births, helperPopulationWithFitnesses(4)[0]), Equals, 1)
+	t.Assert(goga.ReplaceWorst(population, births, helperPopulationWithFitnesses(2)[0]), Equals, 1)
+
+	// An offspring that is less fit than the worst is discarded
+	t.Assert(goga.ReplaceWorst(population, births, helperPopulationWithFitnesses(1)[0]), Equals, -1)
+}
+
+func (s *SteadyStateSuite) TestShouldReplaceOldest(t *C) {
+	population := helperPopulationWithFitnesses(5, 2, 7, 3)
+	t.Assert(goga.ReplaceOldest(population, []int{4, 6, 1, 5}, nil), Equals, 2)
+}
+
+func (s *SteadyStateSuite) TestShouldReplaceRandom(t *C) {
+	population := helperPopulationWithFitnesses(5, 2, 7, 3)
+	births := []int{0, 1, 2, 3}
+
+	seen := make([]bool, len(population))
+	for i := 0; i < 1000; i++ {
+		seen[goga.ReplaceRandom(population, births, nil)] = true
+	}
+	t.Assert(seen, DeepEquals, []bool{true, true, true, true})
+}
+
+func (s *SteadyStateSuite) TestShouldReplaceTournamentLoser(t *C) {
+	population := helperPopulationWithFitnesses(5, 2, 7, 3)
+	births := []int{0, 1, 2, 3}
+
+	// A tournament of one is a random pick
+	seen := make([]bool, len(population))
+	for i := 0; i < 1000; i++ {
+		seen[goga.ReplaceTournamentLoser(1)(population, births, nil)] = true
+	}
+	t.Assert(seen, DeepEquals, []bool{true, true, true, true})
+
+	// A large tournament almost certainly includes the worst
+	for i := 0; i < 100; i++ {
+		t.Assert(goga.ReplaceTournamentLoser(100)(population, births, nil), Equals, 1)
+	}
+}
+
+type MySimulatorIncreasingFitness struct {
+	m             sync.Mutex
+	NumCalls      int
+	NumBeginCalls int
+	NumEndCalls   int
+	nextFitness   int
+}
+
+func (ms *MySimulatorIncreasingFitness) OnBeginSimulation() {
+	ms.NumBeginCalls++
+}
+func (ms *MySimulatorIncreasingFitness) Simulate(g goga.Genome) {
+	ms.m.Lock()
+	ms.NumCalls++
+	ms.nextFitness++
+	g.SetFitness(ms.nextFitness)
+	ms.m.Unlock()
+}
+func (ms *MySimulatorIncreasingFitness) OnEndSimulation() {
+	ms.NumEndCalls++
+}
+func (ms *MySimulatorIncreasingFitness) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+func (s *SteadyStateSuite) TestShouldSimulateOneGenerationPerPopulationSizeEvaluations(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.SteadyState = true
+
+	ms := MySimulatorIncreasingFitness{}
+	genAlgo.Simulator = &ms
+	ec := MyEliteConsumerFitness{}
+	genAlgo.EliteConsumer = &ec
+
+	populationSize := 10
+	genAlgo.Init(populationSize, kNumThreads)
+	genAlgo.Terminator = goga.MaxGenerations(5)
+	genAlgo.Simulate()
+
+	state := genAlgo.GetRunState()
+	t.Assert(state.Generation, Equals, 5)
+	t.Assert(state.Evaluations, Equals, 5*populationSize)
+	t.Assert(ms.NumBeginCalls, Equals, 5)
+	t.Assert(ms.NumEndCalls, Equals, 5)
+
+	// In flight simulations are discarded when the algorithm exits
+	t.Assert(ms.NumCalls >= 5*populationSize, IsTrue)
+	t.Assert(ms.NumCalls <= (5*populationSize)+kNumThreads, IsTrue)
+
+	// Every offspring is fitter than the one before so each generation's elite improves
+	t.Assert(ec.EliteFitnesses, HasLen, 5)
+	for i := 1; i < len(ec.EliteFitnesses); i++ {
+		t.Assert(ec.EliteFitnesses[i] > ec.EliteFitnesses[i-1], IsTrue)
+	}
+	t.Assert(genAlgo.GetPopulation(), HasLen, populationSize)
+}
+
+func (s *SteadyStateSuite) TestShouldUseReplacementFunction(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.SteadyState = true
+	genAlgo.Simulator = &MySimulatorIncreasingFitness{}
+
+	numCalls := 0
+	genAlgo.Replacement = func(population []goga.Genome, births []int, offspring goga.Genome) int {
+		numCalls++
+		if numCalls%2 == 0 {
+			return -1
+		}
+		return goga.ReplaceOldest(population, births, offspring)
+	}
+
+	populationSize := 4
+	genAlgo.Init(populationSize, kNumThreads)
+	initialPopulation := append([]goga.Genome{}, genAlgo.GetPopulation()...)
+
+	genAlgo.Terminator = goga.MaxGenerations(3)
+	genAlgo.Simulate()
+	t.Assert(numCalls, Equals, 2*populationSize)
+
+	// Four offspring were kept, replacing each of the initial population in turn
+	for i, g := range genAlgo.GetPopulation() {
+		t.Assert(g, Not(Equals), initialPopulation[i])
+	}
+}
@@ -0,0 +1,138 @@
+package goga
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// SurvivorFunc -
+// Used in generational mode to pick the next generation, of len('population') genomes,
+// from the current 'population' and the simulated 'offspring' bred from it.
+// 'ages' holds, for each member of the population, the number of generations it
+// has survived, offspring are 0 generations old when they join the population
+type SurvivorFunc func(population []Genome, ages []int, offspring []Genome) []Genome
+
+// sortByFitness sorts 'genomes' from the fittest to the least fit,
+// genomes of equal fitness keep their order
+func sortByFitness(genomes []Genome) {
+	sort.SliceStable(genomes, func(i, j int) bool {
+		return genomes[i].GetFitness() > genomes[j].GetFitness()
+	})
+}
+
+// fillFrom returns 'survivors' topped up to 'size' genomes with the first of 'candidates'
+func fillFrom(survivors []Genome, size int, candidates []Genome) []Genome {
+	if len(survivors) < size {
+		survivors = append(survivors, candidates[:min(size-len(survivors), len(candidates))]...)
+	}
+	return survivors[:min(size, len(survivors))]
+}
+
+// fittest returns a copy of 'genomes' sorted from the fittest to the least fit
+func fittest(genomes []Genome) []Genome {
+	ret := append([]Genome(nil), genomes...)
+	sortByFitness(ret)
+	return ret
+}
+
+// GenerationalSurvival replaces the population with the offspring, in the order
+// they were bred. Offspring that do not fit are dropped, and the fittest of the
+// population survive if there are too few offspring
+func GenerationalSurvival(population []Genome, ages []int, offspring []Genome) []Genome {
+	return fillFrom(append([]Genome(nil), offspring...), len(population), fittest(population))
+}
+
+// PlusSurvival keeps the fittest of the population and the offspring together,
+// the (μ + λ) strategy. The best genome found is never lost
+func PlusSurvival(population []Genome, ages []int, offspring []Genome) []Genome {
+	return fittest(append(append([]Genome(nil), population...), offspring...))[:len(population)]
+}
+
+// CommaSurvival keeps the fittest of the offspring, the (μ, λ) strategy,
+// topped up with the fittest of the population if there are too few offspring
+func CommaSurvival(population []Genome, ages []int, offspring []Genome) []Genome {
+	return fillFrom(fittest(offspring), len(population), fittest(population))
+}
+
+// AgeSurvival replaces the oldest members of the population with the offspring,
+// so no genome survives for long however fit it is. Members of the population of
+// the same age are kept fittest first, and if there are more offspring than fit
+// in the population the fittest of them are kept
+func AgeSurvival(population []Genome, ages []int, offspring []Genome) []Genome {
+	indices := make([]int, len(population))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		a, b := indices[i], indices[j]
+		if ages[a] != ages[b] {
+			return ages[a] < ages[b]
+		}
+		return population[a].GetFitness() > population[b].GetFitness()
+	})
+
+	youngest := make([]Genome, len(indices))
+	for i, index := range indices {
+		youngest[i] = population[index]
+	}
+	return fillFrom(fittest(offspring), len(population), youngest)
+}
+
+// TournamentSurvival returns a SurvivorFunc that fills the next generation one genome at
+// a time with the fittest of 'size' random genomes from the population and the offspring
+// together, the winner of each tournament cannot be picked again
+func TournamentSurvival(size int) SurvivorFunc {
+	return func(population []Genome, ages []int, offspring []Genome) []Genome {
+		candidates := append(append([]Genome(nil), population...), offspring...)
+
+		survivors := make([]Genome, 0, len(population))
+		for len(survivors) < len(population) {
+			winner := rand.Intn(len(candidates))
+			for i := 1; i < size; i++ {
+				contender := rand.Intn(len(candidates))
+				if candidates[contender].GetFitness() > candidates[winner].GetFitness() {
+					winner = contender
+				}
+			}
+
+			survivors = append(survivors, candidates[winner])
+			candidates[winner] = candidates[len(candidates)-1]
+			candidates = candidates[:len(candidates)-1]
+		}
+		return survivors
+	}
+}
+
+// survivors returns the next generation from the simulated 'offspring' with
+// the Survivors function, and records the age of each survivor. It panics if the
+// function does not return a whole population as the population size can't change
+func (ga *GeneticAlgorithm) survivors(offspring []Genome) []Genome {
+	survivorFunc := ga.Survivors
+	if survivorFunc == nil {
+		survivorFunc = GenerationalSurvival
+		if ga.KeepParents {
+			survivorFunc = PlusSurvival
+		} else if len(offspring) != ga.populationSize {
+			survivorFunc = CommaSurvival
+		}
+	}
+
+	survivors := survivorFunc(ga.population, ga.ages, offspring)
+	if len(survivors) != ga.populationSize {
+		panic(fmt.Sprintf("survivor function returned %v genomes but the population size is %v", len(survivors), ga.populationSize))
+	}
+
+	ages := make(map[Genome]int, len(ga.population))
+	for i, g := range ga.population {
+		ages[g] = ga.ages[i] + 1
+	}
+	for _, g := range offspring {
+		ages[g] = 0
+	}
+	ga.ages = make([]int, len(survivors))
+	for i, g := range survivors {
+		ga.ages[i] = ages[g]
+	}
+	return survivors
+}
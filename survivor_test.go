@@ -0,0 +1,96 @@
+package goga_test
+
+import (
+	"sort"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type SurvivorSuite struct {
+}
+
+var _ = Suite(&SurvivorSuite{})
+
+func (s *SurvivorSuite) TestShouldReplaceTheGeneration(t *C) {
+	population := helperPopulationWithFitnesses(5, 2, 7)
+	ages := []int{0, 0, 0}
+
+	survivors := goga.GenerationalSurvival(population, ages, helperPopulationWithFitnesses(1, 3, 2, 9))
+	t.Assert(helperFitnesses(survivors), DeepEquals, []int{1, 3, 2})
+
+	survivors = goga.GenerationalSurvival(population, ages, helperPopulationWithFitnesses(1))
+	t.Assert(helperFitnesses(survivors), DeepEquals, []int{1, 7, 5})
+}
+
+func (s *SurvivorSuite) TestShouldKeepTheFittestOfParentsAndOffspring(t *C) {
+	population := helperPopulationWithFitnesses(5, 2, 7)
+	survivors := goga.PlusSurvival(population, []int{0, 0, 0}, helperPopulationWithFitnesses(1, 6))
+	t.Assert(helperFitnesses(survivors), DeepEquals, []int{7, 6, 5})
+}
+
+func (s *SurvivorSuite) TestShouldKeepTheFittestOffspring(t *C) {
+	population := helperPopulationWithFitnesses(5, 2, 7)
+	survivors := goga.CommaSurvival(population, []int{0, 0, 0}, helperPopulationWithFitnesses(1, 6, 3, 4))
+	t.Assert(helperFitnesses(survivors), DeepEquals, []int{6, 4, 3})
+}
+
+func (s *SurvivorSuite) TestShouldReplaceTheOldest(t *C) {
+	population := helperPopulationWithFitnesses(5, 2, 7, 3)
+	survivors := goga.AgeSurvival(population, []int{2, 0, 3, 0}, helperPopulationWithFitnesses(1, 6))
+	t.Assert(helperFitnesses(survivors), DeepEquals, []int{6, 1, 3, 2})
+}
+
+func (s *SurvivorSuite) TestShouldSurviveTournaments(t *C) {
+	population := helperPopulationWithFitnesses(5, 2, 7)
+	offspring := helperPopulationWithFitnesses(1, 6)
+
+	// A tournament of everyone always picks the fittest remaining genome
+	survivors := goga.TournamentSurvival(100)(population, []int{0, 0, 0}, offspring)
+	t.Assert(helperFitnesses(survivors), DeepEquals, []int{7, 6, 5})
+
+	// Genomes can only survive once
+	for i := 0; i < 100; i++ {
+		survivors = goga.TournamentSurvival(1)(population, []int{0, 0, 0}, offspring)
+		fitnesses := helperFitnesses(survivors)
+		sort.Ints(fitnesses)
+		t.Assert(fitnesses[0] < fitnesses[1] && fitnesses[1] < fitnesses[2], IsTrue)
+	}
+}
+
+func (s *SurvivorSuite) TestShouldPassAgesToSurvivors(t *C) {
+	var passedAges [][]int
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorIncreasingFitness{}
+	genAlgo.NumOffspring = 2
+	genAlgo.Survivors = func(population []goga.Genome, ages []int, offspring []goga.Genome) []goga.Genome {
+		passedAges = append(passedAges, append([]int(nil), ages...))
+		return goga.AgeSurvival(population, ages, offspring)
+	}
+	genAlgo.Terminator = goga.MaxGenerations(5)
+	genAlgo.Init(6, 1)
+	genAlgo.Simulate()
+
+	// The two oldest are replaced each generation, so no genome is older than 2
+	for i := range passedAges {
+		sort.Ints(passedAges[i])
+	}
+	t.Assert(passedAges, DeepEquals, [][]int{
+		{0, 0, 0, 0, 0, 0},
+		{0, 0, 1, 1, 1, 1},
+		{0, 0, 1, 1, 2, 2},
+		{0, 0, 1, 1, 2, 2},
+	})
+}
+
+func (s *SurvivorSuite) TestShouldRejectTooFewSurvivors(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorIncreasingFitness{}
+	genAlgo.Survivors = func(population []goga.Genome, ages []int, offspring []goga.Genome) []goga.Genome {
+		return offspring[:len(offspring)-1]
+	}
+	genAlgo.Terminator = goga.MaxGenerations(3)
+	genAlgo.Init(6, 1)
+
+	t.Assert(func() { genAlgo.Simulate() }, Panics, "survivor function returned 5 genomes but the population size is 6")
+}
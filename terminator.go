@@ -0,0 +1,125 @@
+package goga
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RunState - a snapshot of a running genetic algorithm
+// * Generation - the number of generations that have been simulated
+// * Evaluations - the number of genomes that have been simulated
+// * StartTime - when the current call to Simulate started
+// * Elapsed - time since StartTime
+// * Elite - the elite of the most recent generation
+// * EliteHistory - the elite's fitness for every generation, oldest first
+// * BestFitness - the highest elite fitness seen so far
+// * StagnantGenerations - generations since BestFitness last improved
+// * ParallelSimulations - the number of genomes that can be simulated at once
+type RunState struct {
+	Generation          int
+	Evaluations         int
+	StartTime           time.Time
+	Elapsed             time.Duration
+	Elite               Genome
+	EliteHistory        []int
+	BestFitness         int
+	StagnantGenerations int
+	ParallelSimulations int
+}
+
+// Terminator - an interface to an object that decides when a genetic
+// algorithm should stop, it returns true along with a human readable
+// reason when the run should end
+type Terminator interface {
+	Terminate(*RunState) (bool, string)
+}
+
+// TerminatorFunc - allows an ordinary function to be used as a Terminator
+type TerminatorFunc func(*RunState) (bool, string)
+
+// Terminate calls the function
+func (f TerminatorFunc) Terminate(state *RunState) (bool, string) {
+	return f(state)
+}
+
+// MaxGenerations terminates once 'n' generations have been simulated
+func MaxGenerations(n int) Terminator {
+	return TerminatorFunc(func(state *RunState) (bool, string) {
+		if state.Generation >= n {
+			return true, fmt.Sprintf("reached %v generations", n)
+		}
+		return false, ""
+	})
+}
+
+// Stagnation terminates once the elite's fitness has not improved for 'k' generations
+func Stagnation(k int) Terminator {
+	return TerminatorFunc(func(state *RunState) (bool, string) {
+		if state.StagnantGenerations >= k {
+			return true, fmt.Sprintf("no improvement for %v generations", k)
+		}
+		return false, ""
+	})
+}
+
+// MaxDuration terminates once the run has taken at least 'd'
+func MaxDuration(d time.Duration) Terminator {
+	return TerminatorFunc(func(state *RunState) (bool, string) {
+		if state.Elapsed >= d {
+			return true, fmt.Sprintf("ran for longer than %v", d)
+		}
+		return false, ""
+	})
+}
+
+// TargetFitness terminates once the elite's fitness is at least 'fitness'
+func TargetFitness(fitness int) Terminator {
+	return TerminatorFunc(func(state *RunState) (bool, string) {
+		if state.Elite != nil && state.Elite.GetFitness() >= fitness {
+			return true, fmt.Sprintf("reached target fitness %v", fitness)
+		}
+		return false, ""
+	})
+}
+
+// MaxEvaluations terminates once 'n' genomes have been simulated
+func MaxEvaluations(n int) Terminator {
+	return TerminatorFunc(func(state *RunState) (bool, string) {
+		if state.Evaluations >= n {
+			return true, fmt.Sprintf("reached %v evaluations", n)
+		}
+		return false, ""
+	})
+}
+
+// Any terminates when any of 'terminators' would, reporting the first one's reason
+func Any(terminators ...Terminator) Terminator {
+	return TerminatorFunc(func(state *RunState) (bool, string) {
+		for _, t := range terminators {
+			if terminate, reason := t.Terminate(state); terminate {
+				return true, reason
+			}
+		}
+		return false, ""
+	})
+}
+
+// All terminates only when every one of 'terminators' would, reporting all of their reasons
+func All(terminators ...Terminator) Terminator {
+	return TerminatorFunc(func(state *RunState) (bool, string) {
+		if len(terminators) == 0 {
+			return false, ""
+		}
+
+		reasons := make([]string, 0, len(terminators))
+		for _, t := range terminators {
+			terminate, reason := t.Terminate(state)
+			if !terminate {
+				return false, ""
+			}
+			reasons = append(reasons, reason)
+		}
+		return true, strings.Join(reasons, " and ")
+	})
+}
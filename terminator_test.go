@@ -0,0 +1,163 @@
+package goga_test
+
+import (
+	"time"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type TerminatorSuite struct {
+}
+
+var _ = Suite(&TerminatorSuite{})
+
+func (s *TerminatorSuite) TestShouldTerminateAfterMaxGenerations(t *C) {
+	terminator := goga.MaxGenerations(3)
+
+	terminate, _ := terminator.Terminate(&goga.RunState{Generation: 2})
+	t.Assert(terminate, IsFalse)
+
+	terminate, reason := terminator.Terminate(&goga.RunState{Generation: 3})
+	t.Assert(terminate, IsTrue)
+	t.Assert(reason, Equals, "reached 3 generations")
+}
+
+func (s *TerminatorSuite) TestShouldTerminateOnStagnation(t *C) {
+	terminator := goga.Stagnation(5)
+
+	terminate, _ := terminator.Terminate(&goga.RunState{StagnantGenerations: 4})
+	t.Assert(terminate, IsFalse)
+
+	terminate, reason := terminator.Terminate(&goga.RunState{StagnantGenerations: 5})
+	t.Assert(terminate, IsTrue)
+	t.Assert(reason, Equals, "no improvement for 5 generations")
+}
+
+func (s *TerminatorSuite) TestShouldTerminateAfterMaxDuration(t *C) {
+	terminator := goga.MaxDuration(time.Second)
+
+	terminate, _ := terminator.Terminate(&goga.RunState{Elapsed: time.Millisecond})
+	t.Assert(terminate, IsFalse)
+
+	terminate, reason := terminator.Terminate(&goga.RunState{Elapsed: time.Second})
+	t.Assert(terminate, IsTrue)
+	t.Assert(reason, Equals, "ran for longer than 1s")
+}
+
+func (s *TerminatorSuite) TestShouldTerminateAtTargetFitness(t *C) {
+	terminator := goga.TargetFitness(10)
+	elite := goga.NewGenome(goga.Bitset{})
+
+	terminate, _ := terminator.Terminate(&goga.RunState{})
+	t.Assert(terminate, IsFalse)
+
+	elite.SetFitness(9)
+	terminate, _ = terminator.Terminate(&goga.RunState{Elite: elite})
+	t.Assert(terminate, IsFalse)
+
+	elite.SetFitness(10)
+	terminate, reason := terminator.Terminate(&goga.RunState{Elite: elite})
+	t.Assert(terminate, IsTrue)
+	t.Assert(reason, Equals, "reached target fitness 10")
+}
+
+func (s *TerminatorSuite) TestShouldTerminateAfterMaxEvaluations(t *C) {
+	terminator := goga.MaxEvaluations(100)
+
+	terminate, _ := terminator.Terminate(&goga.RunState{Evaluations: 99})
+	t.Assert(terminate, IsFalse)
+
+	terminate, reason := terminator.Terminate(&goga.RunState{Evaluations: 100})
+	t.Assert(terminate, IsTrue)
+	t.Assert(reason, Equals, "reached 100 evaluations")
+}
+
+func (s *TerminatorSuite) TestShouldCombineWithAny(t *C) {
+	terminator := goga.Any(goga.MaxGenerations(10), goga.MaxEvaluations(100))
+
+	terminate, _ := terminator.Terminate(&goga.RunState{Generation: 1, Evaluations: 1})
+	t.Assert(terminate, IsFalse)
+
+	terminate, reason := terminator.Terminate(&goga.RunState{Generation: 1, Evaluations: 100})
+	t.Assert(terminate, IsTrue)
+	t.Assert(reason, Equals, "reached 100 evaluations")
+
+	terminate, reason = terminator.Terminate(&goga.RunState{Generation: 10, Evaluations: 100})
+	t.Assert(terminate, IsTrue)
+	t.Assert(reason, Equals, "reached 10 generations")
+
+	terminate, _ = goga.Any().Terminate(&goga.RunState{})
+	t.Assert(terminate, IsFalse)
+}
+
+func (s *TerminatorSuite) TestShouldCombineWithAll(t *C) {
+	terminator := goga.All(goga.MaxGenerations(10), goga.MaxEvaluations(100))
+
+	terminate, _ := terminator.Terminate(&goga.RunState{Generation: 1, Evaluations: 100})
+	t.Assert(terminate, IsFalse)
+
+	terminate, reason := terminator.Terminate(&goga.RunState{Generation: 10, Evaluations: 100})
+	t.Assert(terminate, IsTrue)
+	t.Assert(reason, Equals, "reached 10 generations and reached 100 evaluations")
+
+	terminate, _ = goga.All().Terminate(&goga.RunState{})
+	t.Assert(terminate, IsFalse)
+}
+
+func (s *TerminatorSuite) TestShouldNestCombinators(t *C) {
+	terminator := goga.Any(
+		goga.TargetFitness(10),
+		goga.All(goga.MaxGenerations(5), goga.Stagnation(2)),
+	)
+
+	elite := goga.NewGenome(goga.Bitset{})
+	terminate, _ := terminator.Terminate(&goga.RunState{Elite: elite, Generation: 5, StagnantGenerations: 1})
+	t.Assert(terminate, IsFalse)
+
+	terminate, reason := terminator.Terminate(&goga.RunState{Elite: elite, Generation: 5, StagnantGenerations: 2})
+	t.Assert(terminate, IsTrue)
+	t.Assert(reason, Equals, "reached 5 generations and no improvement for 2 generations")
+}
+
+func (s *TerminatorSuite) TestShouldStopGeneticAlgorithmWithTerminator(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	ms := MySimulatorCounter{}
+	genAlgo.Simulator = &ms
+	genAlgo.Terminator = goga.Any(goga.MaxGenerations(7), goga.MaxEvaluations(1000))
+
+	populationSize := 10
+	genAlgo.Init(populationSize, kNumThreads)
+	t.Assert(genAlgo.Simulate(), IsTrue)
+
+	state := genAlgo.GetRunState()
+	t.Assert(state.Generation, Equals, 7)
+	t.Assert(state.Evaluations, Equals, 7*populationSize)
+	t.Assert(state.EliteHistory, HasLen, 7)
+	t.Assert(ms.NumCalls, Equals, 7*populationSize)
+	t.Assert(genAlgo.GetTerminationReason(), Equals, "reached 7 generations")
+}
+
+func (s *TerminatorSuite) TestShouldTrackStagnationInRunState(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorFitnessSequence{Fitnesses: []int{1, 3, 2, 3, 2}}
+	genAlgo.Terminator = goga.Stagnation(3)
+	genAlgo.Init(2, kNumThreads)
+	genAlgo.Simulate()
+
+	state := genAlgo.GetRunState()
+	t.Assert(state.EliteHistory, DeepEquals, []int{1, 3, 2, 3, 2})
+	t.Assert(state.BestFitness, Equals, 3)
+	t.Assert(state.StagnantGenerations, Equals, 3)
+	t.Assert(genAlgo.GetTerminationReason(), Equals, "no improvement for 3 generations")
+}
+
+func (s *TerminatorSuite) TestShouldPreferExitFunctionOverTerminator(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Terminator = goga.MaxGenerations(100)
+	genAlgo.Init(2, kNumThreads)
+	genAlgo.SimulateUntil(helperGenerateExitFunction(3))
+
+	t.Assert(genAlgo.GetRunState().Generation, Equals, 3)
+	t.Assert(genAlgo.GetTerminationReason(), Equals, "exit function returned true")
+}
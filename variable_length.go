@@ -0,0 +1,188 @@
+package goga
+
+import (
+	"math/rand"
+)
+
+// maxCutAndSpliceAttempts is the number of pairs of cut points tried before
+// CutAndSplice gives up on finding offspring within the length bounds
+const maxCutAndSpliceAttempts = 10
+
+// LengthConfig -
+// Describes genomes whose length is a number of fixed size blocks, for problems where
+// the size of a solution isn't known up front, for example a genome of shapes where
+// each shape is a block
+// * BlockSize - the number of bits in a block, genomes grow and shrink a block at a time
+// * MinBlocks - the fewest blocks a genome can shrink to
+// * MaxBlocks - the most blocks a genome can grow to, 0 for no limit
+//
+// The operators returned by its methods can be used as the 'F' of a
+// MaterFunctionProbability. Bits after the last whole block are left in place
+type LengthConfig struct {
+	BlockSize int
+	MinBlocks int
+	MaxBlocks int
+}
+
+func (lc *LengthConfig) numBlocks(b *Bitset) int {
+	if lc.BlockSize <= 0 {
+		return 0
+	}
+	return b.GetSize() / lc.BlockSize
+}
+
+func (lc *LengthConfig) inBounds(numBlocks int) bool {
+	return numBlocks >= lc.MinBlocks && (lc.MaxBlocks <= 0 || numBlocks <= lc.MaxBlocks)
+}
+
+// splice returns the bits of 'a' before 'aEnd' followed by the bits of 'b' from 'bStart'
+func splice(a *Bitset, aEnd int, b *Bitset, bStart int) Bitset {
+	ret := Bitset{}
+	ret.Create(aEnd + b.GetSize() - bStart)
+	for i := 0; i < aEnd; i++ {
+		ret.Set(i, a.Get(i))
+	}
+	for i := bStart; i < b.GetSize(); i++ {
+		ret.Set(aEnd+i-bStart, b.Get(i))
+	}
+	return ret
+}
+
+// insertBlock returns a copy of 'b' with 'block' inserted at bit 'index'
+func insertBlock(b *Bitset, index int, block *Bitset) Bitset {
+	ret := Bitset{}
+	ret.Create(b.GetSize() + block.GetSize())
+	for i := 0; i < index; i++ {
+		ret.Set(i, b.Get(i))
+	}
+	for i := 0; i < block.GetSize(); i++ {
+		ret.Set(index+i, block.Get(i))
+	}
+	for i := index; i < b.GetSize(); i++ {
+		ret.Set(block.GetSize()+i, b.Get(i))
+	}
+	return ret
+}
+
+// BlockInsertion returns a mater function that inserts a block of random bits
+// at a random block boundary of the first genome, if it has fewer than MaxBlocks
+// i.e. with a BlockSize of 2
+// input genomes of:
+// 0000 and 1111
+// could produce output genomes of:
+// 001000 and 1111
+func (lc LengthConfig) BlockInsertion() func(Genome, Genome) (Genome, Genome) {
+	return func(g1, g2 Genome) (Genome, Genome) {
+		g1Bits := g1.GetBits()
+		numBlocks := lc.numBlocks(g1Bits)
+		if lc.BlockSize <= 0 || !lc.inBounds(numBlocks+1) {
+			return NewGenome(g1Bits.CreateCopy()), NewGenome(*g2.GetBits())
+		}
+
+		block := NewRandomBitsetCreate(lc.BlockSize).Go()
+		index := rand.Intn(numBlocks+1) * lc.BlockSize
+		return NewGenome(insertBlock(g1Bits, index, &block)), NewGenome(*g2.GetBits())
+	}
+}
+
+// BlockDeletion returns a mater function that removes a random block
+// from the first genome, if it has more than MinBlocks
+// i.e. with a BlockSize of 2
+// input genomes of:
+// 001100 and 1111
+// could produce output genomes of:
+// 0000 and 1111
+func (lc LengthConfig) BlockDeletion() func(Genome, Genome) (Genome, Genome) {
+	return func(g1, g2 Genome) (Genome, Genome) {
+		g1Bits := g1.GetBits()
+		numBlocks := lc.numBlocks(g1Bits)
+		if numBlocks == 0 || !lc.inBounds(numBlocks-1) {
+			return NewGenome(g1Bits.CreateCopy()), NewGenome(*g2.GetBits())
+		}
+
+		index := rand.Intn(numBlocks) * lc.BlockSize
+		return NewGenome(splice(g1Bits, index, g1Bits, index+lc.BlockSize)), NewGenome(*g2.GetBits())
+	}
+}
+
+// BlockDuplication returns a mater function that copies a random block of the
+// first genome and inserts the copy after it, if it has fewer than MaxBlocks
+// i.e. with a BlockSize of 2
+// input genomes of:
+// 001100 and 1111
+// could produce output genomes of:
+// 00111100 and 1111
+func (lc LengthConfig) BlockDuplication() func(Genome, Genome) (Genome, Genome) {
+	return func(g1, g2 Genome) (Genome, Genome) {
+		g1Bits := g1.GetBits()
+		numBlocks := lc.numBlocks(g1Bits)
+		if numBlocks == 0 || !lc.inBounds(numBlocks+1) {
+			return NewGenome(g1Bits.CreateCopy()), NewGenome(*g2.GetBits())
+		}
+
+		index := rand.Intn(numBlocks) * lc.BlockSize
+		block := g1Bits.Slice(index, lc.BlockSize)
+		return NewGenome(insertBlock(g1Bits, index+lc.BlockSize, &block)), NewGenome(*g2.GetBits())
+	}
+}
+
+// CutAndSplice returns a mater function that picks a separate block boundary in each
+// genome and swaps everything after them, so the offspring can differ in length from
+// their parents. Cut points are chosen so both offspring are within the bounds, the
+// parents are copied unchanged if no such cut points are found
+// i.e. with a BlockSize of 2
+// input genomes of:
+// 000000 and 1111
+// could produce output genomes of:
+// 0011 and 110000
+func (lc LengthConfig) CutAndSplice() func(Genome, Genome) (Genome, Genome) {
+	return func(g1, g2 Genome) (Genome, Genome) {
+		g1Bits, g2Bits := g1.GetBits(), g2.GetBits()
+		g1Blocks, g2Blocks := lc.numBlocks(g1Bits), lc.numBlocks(g2Bits)
+
+		if lc.BlockSize > 0 {
+			for attempt := 0; attempt < maxCutAndSpliceAttempts; attempt++ {
+				cut1, cut2 := rand.Intn(g1Blocks+1), rand.Intn(g2Blocks+1)
+				if !lc.inBounds(cut1+g2Blocks-cut2) || !lc.inBounds(cut2+g1Blocks-cut1) {
+					continue
+				}
+
+				cut1, cut2 = cut1*lc.BlockSize, cut2*lc.BlockSize
+				return NewGenome(splice(g1Bits, cut1, g2Bits, cut2)), NewGenome(splice(g2Bits, cut2, g1Bits, cut1))
+			}
+		}
+		return NewGenome(g1Bits.CreateCopy()), NewGenome(g2Bits.CreateCopy())
+	}
+}
+
+// LengthPenaltySimulator -
+// Wraps a Simulator to discourage genomes from growing without improving.
+// After the wrapped Simulator has set a genome's fitness, PenaltyPerBlock is
+// subtracted for each of the genome's blocks, and the fitness of genomes outside
+// of the LengthConfig's bounds is set to 0. Fitness never goes below 0
+type LengthPenaltySimulator struct {
+	Simulator
+	LengthConfig    LengthConfig
+	PenaltyPerBlock int
+}
+
+// NewLengthPenaltySimulator returns a LengthPenaltySimulator wrapping 'simulator'
+func NewLengthPenaltySimulator(simulator Simulator, lengthConfig LengthConfig, penaltyPerBlock int) Simulator {
+	return &LengthPenaltySimulator{
+		Simulator:       simulator,
+		LengthConfig:    lengthConfig,
+		PenaltyPerBlock: penaltyPerBlock,
+	}
+}
+
+// Simulate simulates 'g' with the wrapped Simulator then applies the length penalty
+func (lps *LengthPenaltySimulator) Simulate(g Genome) {
+	lps.Simulator.Simulate(g)
+
+	numBlocks := lps.LengthConfig.numBlocks(g.GetBits())
+	fitness := g.GetFitness() - lps.PenaltyPerBlock*numBlocks
+	if fitness < 0 || !lps.LengthConfig.inBounds(numBlocks) {
+		fitness = 0
+	}
+	g.SetFitness(fitness)
+}
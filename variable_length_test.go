@@ -0,0 +1,168 @@
+package goga_test
+
+import (
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type VariableLengthSuite struct {
+}
+
+var _ = Suite(&VariableLengthSuite{})
+
+func helperGenome(t *C, bits string) goga.Genome {
+	return goga.NewGenome(*helperBitset(t, bits))
+}
+
+func (s *VariableLengthSuite) TestShouldInsertBlocks(t *C) {
+	config := goga.LengthConfig{BlockSize: 2, MaxBlocks: 3}
+	insert := config.BlockInsertion()
+
+	for i := 0; i < 20; i++ {
+		g1, g2 := insert(helperGenome(t, "1111"), helperGenome(t, "0000"))
+		t.Assert(g2.GetBits().String(), Equals, "0000")
+
+		bits := g1.GetBits().String()
+		t.Assert(len(bits), Equals, 6)
+
+		// The new block is at a block boundary so the existing blocks are intact
+		matched := bits[:4] == "1111" || bits[2:] == "1111" || (bits[:2] == "11" && bits[4:] == "11")
+		t.Assert(matched, IsTrue, Commentf(bits))
+	}
+
+	// Already at MaxBlocks
+	g1, _ := insert(helperGenome(t, "111111"), helperGenome(t, "0000"))
+	t.Assert(g1.GetBits().String(), Equals, "111111")
+}
+
+func (s *VariableLengthSuite) TestShouldDeleteBlocks(t *C) {
+	config := goga.LengthConfig{BlockSize: 2, MinBlocks: 2}
+	remove := config.BlockDeletion()
+
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		g1, g2 := remove(helperGenome(t, "0011101"), helperGenome(t, "0000"))
+		t.Assert(g2.GetBits().String(), Equals, "0000")
+		seen[g1.GetBits().String()] = true
+	}
+
+	// The trailing bit is not part of a block and is never removed
+	t.Assert(seen, DeepEquals, map[string]bool{"11101": true, "00101": true, "00111": true})
+
+	// Already at MinBlocks
+	g1, _ := remove(helperGenome(t, "0011"), helperGenome(t, "0000"))
+	t.Assert(g1.GetBits().String(), Equals, "0011")
+}
+
+func (s *VariableLengthSuite) TestShouldDuplicateBlocks(t *C) {
+	config := goga.LengthConfig{BlockSize: 2}
+	duplicate := config.BlockDuplication()
+
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		g1, _ := duplicate(helperGenome(t, "0011"), helperGenome(t, "0000"))
+		seen[g1.GetBits().String()] = true
+	}
+	t.Assert(seen, DeepEquals, map[string]bool{"000011": true, "001111": true})
+}
+
+func (s *VariableLengthSuite) TestShouldNotChangeLengthWithoutBlocks(t *C) {
+	config := goga.LengthConfig{}
+	for _, f := range []func(goga.Genome, goga.Genome) (goga.Genome, goga.Genome){
+		config.BlockInsertion(), config.BlockDeletion(), config.BlockDuplication(), config.CutAndSplice(),
+	} {
+		g1, g2 := f(helperGenome(t, "0011"), helperGenome(t, "01"))
+		t.Assert(g1.GetBits().String(), Equals, "0011")
+		t.Assert(g2.GetBits().String(), Equals, "01")
+	}
+}
+
+func (s *VariableLengthSuite) TestShouldCutAndSplice(t *C) {
+	config := goga.LengthConfig{BlockSize: 2}
+	cutAndSplice := config.CutAndSplice()
+
+	lengths := map[int]bool{}
+	for i := 0; i < 500; i++ {
+		g1, g2 := cutAndSplice(helperGenome(t, "000000"), helperGenome(t, "1111"))
+		b1, b2 := g1.GetBits().String(), g2.GetBits().String()
+
+		// No bits are lost or created, and each offspring is a prefix of one
+		// parent followed by a suffix of the other
+		t.Assert(len(b1)+len(b2), Equals, 10)
+		t.Assert(b1, Matches, "(00)*(11)*")
+		t.Assert(b2, Matches, "(11)*(00)*")
+		lengths[len(b1)] = true
+	}
+	t.Assert(lengths, DeepEquals, map[int]bool{0: true, 2: true, 4: true, 6: true, 8: true, 10: true})
+}
+
+func (s *VariableLengthSuite) TestShouldCutAndSpliceWithinBounds(t *C) {
+	config := goga.LengthConfig{BlockSize: 1, MinBlocks: 3, MaxBlocks: 5}
+	cutAndSplice := config.CutAndSplice()
+
+	for i := 0; i < 100; i++ {
+		g1, g2 := cutAndSplice(helperGenome(t, "00000"), helperGenome(t, "111"))
+		t.Assert(g1.GetBits().GetSize() >= 3 && g1.GetBits().GetSize() <= 5, IsTrue)
+		t.Assert(g2.GetBits().GetSize() >= 3 && g2.GetBits().GetSize() <= 5, IsTrue)
+	}
+}
+
+type MySimulatorConstantFitness struct {
+	goga.NullSimulator
+	Fitness int
+}
+
+func (ms *MySimulatorConstantFitness) Simulate(g goga.Genome) {
+	g.SetFitness(ms.Fitness)
+}
+
+func (s *VariableLengthSuite) TestShouldPenaliseLength(t *C) {
+	config := goga.LengthConfig{BlockSize: 2, MinBlocks: 1, MaxBlocks: 4}
+	simulator := goga.NewLengthPenaltySimulator(&MySimulatorConstantFitness{Fitness: 100}, config, 10)
+
+	for _, test := range []struct {
+		bits    string
+		fitness int
+	}{
+		{"00", 90},
+		{"000000", 70},
+		{"0000000", 70},
+		{"", 0},
+		{"0000000000", 0},
+	} {
+		g := helperGenome(t, test.bits)
+		simulator.Simulate(g)
+		t.Assert(g.GetFitness(), Equals, test.fitness, Commentf(test.bits))
+	}
+
+	simulator = goga.NewLengthPenaltySimulator(&MySimulatorConstantFitness{Fitness: 5}, config, 10)
+	g := helperGenome(t, "00")
+	simulator.Simulate(g)
+	t.Assert(g.GetFitness(), Equals, 0)
+}
+
+func (s *VariableLengthSuite) TestShouldEvolveVariableLengthGenomes(t *C) {
+	config := goga.LengthConfig{BlockSize: 4, MinBlocks: 1, MaxBlocks: 8}
+
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = goga.NewLengthPenaltySimulator(&MySimulatorConstantFitness{Fitness: 100}, config, 1)
+	genAlgo.BitsetCreate = goga.NewRandomBitsetCreate(16)
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 0.5, F: config.CutAndSplice()},
+		{P: 0.2, F: config.BlockInsertion()},
+		{P: 0.2, F: config.BlockDuplication()},
+		{P: 0.2, F: config.BlockDeletion()},
+	})
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1.0, F: goga.Roulette},
+	})
+	genAlgo.Terminator = goga.MaxGenerations(20)
+	genAlgo.Init(20, 1)
+	genAlgo.Simulate()
+
+	for _, g := range genAlgo.GetPopulation() {
+		size := g.GetBits().GetSize()
+		t.Assert(size%4, Equals, 0)
+		t.Assert(size >= 4 && size <= 32, IsTrue)
+	}
+}
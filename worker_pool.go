@@ -0,0 +1,48 @@
+package goga
+
+import (
+	"sync"
+)
+
+// workerPool - a fixed number of goroutines that run submitted tasks,
+// the goroutines live for the whole of a call to Simulate rather than
+// being created for every generation
+type workerPool struct {
+	tasks     chan func()
+	waitGroup sync.WaitGroup
+}
+
+func newWorkerPool(size int) *workerPool {
+	if size < 1 {
+		size = 1
+	}
+
+	p := &workerPool{
+		tasks: make(chan func()),
+	}
+	for i := 0; i < size; i++ {
+		go func() {
+			for task := range p.tasks {
+				task()
+				p.waitGroup.Done()
+			}
+		}()
+	}
+	return p
+}
+
+// submit blocks until a worker is free to run 'task'
+func (p *workerPool) submit(task func()) {
+	p.waitGroup.Add(1)
+	p.tasks <- task
+}
+
+// wait blocks until every submitted task has finished
+func (p *workerPool) wait() {
+	p.waitGroup.Wait()
+}
+
+// close stops the workers once they have finished their current tasks
+func (p *workerPool) close() {
+	close(p.tasks)
+}